@@ -4,8 +4,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"net"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/senzing-garage/go-cmdhelping/cmdhelper"
@@ -33,6 +36,32 @@ An HTTP server supporting the following services:
     `
 )
 
+var errForPackage = errors.New("cmd")
+
+var adminToken = option.ContextVariable{
+	Arg:     "admin-token",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_ADMIN_TOKEN", ""),
+	Envar:   "SENZING_TOOLS_ADMIN_TOKEN",
+	Help:    "Bearer token of the admin API at /admin/. Prefer the environment variable. Default: no admin API [%s]",
+	Type:    optiontype.String,
+}
+
+var allowedCIDRs = option.ContextVariable{
+	Arg:     "allowed-cidrs",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_ALLOWED_CIDRS",
+	Help:    "Comma-delimited list of client CIDRs to serve. Prefix service= to scope (e.g. xterm=10.0.0.0/8) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var apiInstances = option.ContextVariable{
+	Arg:     "api-instances",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_API_INSTANCES",
+	Help:    "Comma-delimited list of name=grpcURL Senzing REST APIs at /api/name/ (e.g. sales=grpc://host:8261) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
 var avoidServe = option.ContextVariable{
 	Arg:     "avoid-serving",
 	Default: option.OsLookupEnvBool("SENZING_TOOLS_AVOID_SERVING", false),
@@ -41,27 +70,327 @@ var avoidServe = option.ContextVariable{
 	Type:    optiontype.Bool,
 }
 
+var compressionContentTypes = option.ContextVariable{
+	Arg:     "compression-content-types",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_COMPRESSION_CONTENT_TYPES",
+	Help:    "Comma-delimited list of media types to compress. Default: JSON, JavaScript, CSS, HTML, and text [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var compressionMinSize = option.ContextVariable{
+	Arg:     "compression-min-size",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_COMPRESSION_MIN_SIZE", 1024),
+	Envar:   "SENZING_TOOLS_COMPRESSION_MIN_SIZE",
+	Help:    "Minimum size in bytes of a response to compress [%s]",
+	Type:    optiontype.Int,
+}
+
+var corsAllowCredentials = option.ContextVariable{
+	Arg:     "cors-allow-credentials",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_CORS_ALLOW_CREDENTIALS", false),
+	Envar:   "SENZING_TOOLS_CORS_ALLOW_CREDENTIALS",
+	Help:    "Allow cross-origin requests to the Senzing REST API to include credentials [%s]",
+	Type:    optiontype.Bool,
+}
+
+var corsAllowedHeaders = option.ContextVariable{
+	Arg:     "cors-allowed-headers",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_CORS_ALLOWED_HEADERS",
+	Help:    "Comma-delimited list of request headers allowed in cross-origin requests [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var corsAllowedMethods = option.ContextVariable{
+	Arg:     "cors-allowed-methods",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_CORS_ALLOWED_METHODS",
+	Help:    "Comma-delimited list of HTTP methods allowed in cross-origin requests [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var corsAllowedOrigins = option.ContextVariable{
+	Arg:     "cors-allowed-origins",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_CORS_ALLOWED_ORIGINS",
+	Help:    "Comma-delimited list of origins allowed to call the Senzing REST API (e.g. https://*.example.com) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var corsMaxAge = option.ContextVariable{
+	Arg:     "cors-max-age",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_CORS_MAX_AGE", 0),
+	Envar:   "SENZING_TOOLS_CORS_MAX_AGE",
+	Help:    "Seconds a browser may cache a cross-origin preflight response [%s]",
+	Type:    optiontype.Int,
+}
+
+var deniedCIDRs = option.ContextVariable{
+	Arg:     "denied-cidrs",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_DENIED_CIDRS",
+	Help:    "Comma-delimited list of client CIDRs to refuse. Prefix service= to scope (e.g. xterm=0.0.0.0/0) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var enableCompression = option.ContextVariable{
+	Arg:     "enable-compression",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_ENABLE_COMPRESSION", false),
+	Envar:   "SENZING_TOOLS_ENABLE_COMPRESSION",
+	Help:    "Compress responses with brotli, zstd, or gzip as negotiated with the client [%s]",
+	Type:    optiontype.Bool,
+}
+
+var enableGrpcWeb = option.ContextVariable{
+	Arg:     "enable-grpc-web",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_ENABLE_GRPC_WEB", false),
+	Envar:   "SENZING_TOOLS_ENABLE_GRPC_WEB",
+	Help:    "Enable a gRPC-Web gateway at /grpc-web to the Senzing gRPC server of --grpc-url [%s]",
+	Type:    optiontype.Bool,
+}
+
+var enableH2C = option.ContextVariable{
+	Arg:     "enable-h2c",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_ENABLE_H2C", false),
+	Envar:   "SENZING_TOOLS_ENABLE_H2C",
+	Help:    "Serve HTTP/2 without TLS, for service meshes that terminate TLS [%s]",
+	Type:    optiontype.Bool,
+}
+
+var grpcLoadBalancing = option.ContextVariable{
+	Arg:     "grpc-load-balancing",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_GRPC_LOAD_BALANCING", httpserver.GrpcLoadBalancingRoundRobin),
+	Envar:   "SENZING_TOOLS_GRPC_LOAD_BALANCING",
+	Help:    "How requests are balanced across --grpc-urls: round_robin or least_request [%s]",
+	Type:    optiontype.String,
+}
+
+var grpcURLs = option.ContextVariable{
+	Arg:     "grpc-urls",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_GRPC_URLS",
+	Help:    "Comma-delimited list of Senzing gRPC URLs, of one scheme and options, to balance across [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var hstsMaxAge = option.ContextVariable{
+	Arg:     "hsts-max-age",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_HSTS_MAX_AGE", 0),
+	Envar:   "SENZING_TOOLS_HSTS_MAX_AGE",
+	Help:    "Seconds for Strict-Transport-Security on requests over TLS or from --trusted-proxies. 0 disables [%s]",
+	Type:    optiontype.Int,
+}
+
+var logLevelRevertAfter = option.ContextVariable{
+	Arg:     "log-level-revert-after",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_LOG_LEVEL_REVERT_AFTER", 0),
+	Envar:   "SENZING_TOOLS_LOG_LEVEL_REVERT_AFTER",
+	Help:    "Seconds a log level set through the admin API, SIGUSR1, or SIGUSR2 lasts. 0 uses 900 [%s]",
+	Type:    optiontype.Int,
+}
+
+var maintenance = option.ContextVariable{
+	Arg:     "maintenance",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_MAINTENANCE", false),
+	Envar:   "SENZING_TOOLS_MAINTENANCE",
+	Help:    "Start in maintenance mode, refusing Senzing REST API writes with 503 [%s]",
+	Type:    optiontype.Bool,
+}
+
+var maintenanceDisablesXterm = option.ContextVariable{
+	Arg:     "maintenance-disables-xterm",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_MAINTENANCE_DISABLES_XTERM", false),
+	Envar:   "SENZING_TOOLS_MAINTENANCE_DISABLES_XTERM",
+	Help:    "In maintenance mode, refuse XTerm too [%s]",
+	Type:    optiontype.Bool,
+}
+
+var maintenanceMessage = option.ContextVariable{
+	Arg:     "maintenance-message",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_MAINTENANCE_MESSAGE", ""),
+	Envar:   "SENZING_TOOLS_MAINTENANCE_MESSAGE",
+	Help:    "Message shown on Console pages and in 503 responses during maintenance [%s]",
+	Type:    optiontype.String,
+}
+
+var maintenanceRetryAfter = option.ContextVariable{
+	Arg:     "maintenance-retry-after",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_MAINTENANCE_RETRY_AFTER", 0),
+	Envar:   "SENZING_TOOLS_MAINTENANCE_RETRY_AFTER",
+	Help:    "Seconds clients are asked to wait before retrying during maintenance. 0 uses 300 [%s]",
+	Type:    optiontype.Int,
+}
+
+var maxHeaderBytes = option.ContextVariable{
+	Arg:     "max-header-bytes",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_MAX_HEADER_BYTES", 0),
+	Envar:   "SENZING_TOOLS_MAX_HEADER_BYTES",
+	Help:    "Maximum bytes of HTTP request headers. 0 uses the Go default of 1MB [%s]",
+	Type:    optiontype.Int,
+}
+
+var maxRequestBodyBytes = option.ContextVariable{
+	Arg:     "max-request-body-bytes",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_MAX_REQUEST_BODY_BYTES",
+	Help:    "Comma-delimited list of name=bytes request body limits (e.g. console=65536,api/write=134217728) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var mirrorPercent = option.ContextVariable{
+	Arg:     "mirror-percent",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_MIRROR_PERCENT", 0),
+	Envar:   "SENZING_TOOLS_MIRROR_PERCENT",
+	Help:    "Percentage of read-only Senzing REST API requests copied to the mirror URL [%s]",
+	Type:    optiontype.Int,
+}
+
+var mirrorReportFile = option.ContextVariable{
+	Arg:     "mirror-report-file",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_MIRROR_REPORT_FILE", ""),
+	Envar:   "SENZING_TOOLS_MIRROR_REPORT_FILE",
+	Help:    "File to which latencies and response differences of mirrored requests are appended as JSON lines [%s]",
+	Type:    optiontype.String,
+}
+
+var mirrorURL = option.ContextVariable{
+	Arg:     "mirror-url",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_MIRROR_URL", ""),
+	Envar:   "SENZING_TOOLS_MIRROR_URL",
+	Help:    "Base URL of a shadow server receiving copies of read-only /api requests (e.g. http://host:8261) [%s]",
+	Type:    optiontype.String,
+}
+
+var rateLimitKeyHeader = option.ContextVariable{
+	Arg:     "rate-limit-key-header",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_RATE_LIMIT_KEY_HEADER", ""),
+	Envar:   "SENZING_TOOLS_RATE_LIMIT_KEY_HEADER",
+	Help:    "HTTP header whose values are rate limited as well as client IP addresses (e.g. X-API-Key) [%s]",
+	Type:    optiontype.String,
+}
+
+var rateLimits = option.ContextVariable{
+	Arg:     "rate-limits",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_RATE_LIMITS",
+	Help:    "Comma-delimited list of name=requestsPerSecond:burst (e.g. api=10:20,api/search=2:5,xterm=1:5) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var reverseProxies = option.ContextVariable{
+	Arg:     "reverse-proxies",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_REVERSE_PROXIES",
+	Help:    "Comma-delimited list of name=upstreamURL services forwarding /name/ (e.g. legacy=http://host:8250) [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var staticCacheMaxAge = option.ContextVariable{
+	Arg:     "static-cache-max-age",
+	Default: option.OsLookupEnvInt("SENZING_TOOLS_STATIC_CACHE_MAX_AGE", 0),
+	Envar:   "SENZING_TOOLS_STATIC_CACHE_MAX_AGE",
+	Help:    "Seconds a browser may reuse static assets without revalidating. Default: always revalidate [%s]",
+	Type:    optiontype.Int,
+}
+
+var staticDirectory = option.ContextVariable{
+	Arg:     "static-directory",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_STATIC_DIRECTORY", ""),
+	Envar:   "SENZING_TOOLS_STATIC_DIRECTORY",
+	Help:    "Directory of static files, like a single-page application, served at / over the embedded files [%s]",
+	Type:    optiontype.String,
+}
+
+var staticDirectoryExclusive = option.ContextVariable{
+	Arg:     "static-directory-exclusive",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_STATIC_DIRECTORY_EXCLUSIVE", false),
+	Envar:   "SENZING_TOOLS_STATIC_DIRECTORY_EXCLUSIVE",
+	Help:    "Serve only the static directory at /, not the embedded files [%s]",
+	Type:    optiontype.Bool,
+}
+
+var staticSPAFallback = option.ContextVariable{
+	Arg:     "static-spa-fallback",
+	Default: option.OsLookupEnvBool("SENZING_TOOLS_STATIC_SPA_FALLBACK", false),
+	Envar:   "SENZING_TOOLS_STATIC_SPA_FALLBACK",
+	Help:    "Serve the static directory's index.html for unknown routes without a file extension [%s]",
+	Type:    optiontype.Bool,
+}
+
+var trustedProxies = option.ContextVariable{
+	Arg:     "trusted-proxies",
+	Default: []string{},
+	Envar:   "SENZING_TOOLS_TRUSTED_PROXIES",
+	Help:    "Comma-delimited list of CIDRs of TLS-terminating proxies whose X-Forwarded-Proto is believed [%s]",
+	Type:    optiontype.StringSlice,
+}
+
+var templatesDirectory = option.ContextVariable{
+	Arg:     "templates-directory",
+	Default: option.OsLookupEnvString("SENZING_TOOLS_TEMPLATES_DIRECTORY", ""),
+	Envar:   "SENZING_TOOLS_TEMPLATES_DIRECTORY",
+	Help:    "Directory of HTML templates served as extra Console pages under /site/ [%s]",
+	Type:    optiontype.String,
+}
+
 // ----------------------------------------------------------------------------
 // Context variables
 // ----------------------------------------------------------------------------
 
 var ContextVariablesForMultiPlatform = []option.ContextVariable{
+	adminToken,
+	allowedCIDRs,
+	apiInstances,
 	avoidServe,
+	compressionContentTypes,
+	compressionMinSize,
 	option.Configuration,
 	option.CoreInstanceName,
 	option.CoreLogLevel,
 	option.CoreSettings,
+	corsAllowCredentials,
+	corsAllowedHeaders,
+	corsAllowedMethods,
+	corsAllowedOrigins,
+	corsMaxAge,
 	option.DatabaseURL,
+	deniedCIDRs,
 	option.EnableAll,
+	enableCompression,
+	enableGrpcWeb,
+	enableH2C,
 	option.EnableSenzingRestAPI,
 	option.EnableSwaggerUI,
 	option.EnableXterm,
+	grpcLoadBalancing,
 	option.GrpcURL,
+	grpcURLs,
+	hstsMaxAge,
 	option.HTTPPort,
 	option.LogLevel,
+	logLevelRevertAfter,
+	maintenance,
+	maintenanceDisablesXterm,
+	maintenanceMessage,
+	maintenanceRetryAfter,
+	maxHeaderBytes,
+	maxRequestBodyBytes,
+	mirrorPercent,
+	mirrorReportFile,
+	mirrorURL,
 	option.ObserverOrigin,
 	option.ObserverURL,
+	rateLimitKeyHeader,
+	rateLimits,
+	reverseProxies,
 	option.ServerAddress,
+	staticCacheMaxAge,
+	staticDirectory,
+	staticDirectoryExclusive,
+	staticSPAFallback,
+	templatesDirectory,
+	trustedProxies,
 	option.TtyOnly,
 	option.XtermAllowedHostnames.SetDefault(getDefaultAllowedHostnames()),
 	option.XtermArguments,
@@ -131,40 +460,160 @@ func RunE(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	grpcTargets := []string{}
+	balancedGrpcURLs := viper.GetStringSlice(grpcURLs.Arg)
+
+	if len(grpcURL) > 0 && len(balancedGrpcURLs) > 0 {
+		return wraperror.Errorf(errForPackage, "%s and %s cannot both be set", option.GrpcURL.Arg, grpcURLs.Arg)
+	}
+
+	for _, balancedGrpcURL := range balancedGrpcURLs {
+		// The servers are dialed with the same options, so their URLs must agree on everything but the address.
+		err = checkSameGrpcDialSettings(balancedGrpcURLs[0], balancedGrpcURL)
+		if err != nil {
+			return wraperror.Errorf(err, "%s", grpcURLs.Arg)
+		}
+
+		balancedGrpcTarget, balancedGrpcDialOptions, err := grpcurl.Parse(ctx, balancedGrpcURL)
+		if err != nil {
+			return wraperror.Errorf(err, "grpcurl.Parse: %s", balancedGrpcURL)
+		}
+
+		grpcDialOptions = balancedGrpcDialOptions
+		grpcTargets = append(grpcTargets, balancedGrpcTarget)
+	}
+
+	// Parse API instances.
+
+	parsedAPIInstances, err := parseAPIInstances(ctx, viper.GetStringSlice(apiInstances.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "parseAPIInstances")
+	}
+
+	// Parse rate limits.
+
+	parsedRateLimits, err := httpserver.ParseRateLimits(viper.GetStringSlice(rateLimits.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseRateLimits")
+	}
+
+	// Parse request body limits.
+
+	parsedMaxRequestBodyBytes, err := httpserver.ParseMaxRequestBodyBytes(viper.GetStringSlice(maxRequestBodyBytes.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseMaxRequestBodyBytes")
+	}
+
+	// Parse reverse proxies.
+
+	parsedReverseProxies, err := httpserver.ParseReverseProxies(viper.GetStringSlice(reverseProxies.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseReverseProxies")
+	}
+
+	// Parse client CIDRs.
+
+	parsedAllowedCIDRs, serviceAllowedCIDRs, err := httpserver.ParseCIDRs(viper.GetStringSlice(allowedCIDRs.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseCIDRs: %s", allowedCIDRs.Arg)
+	}
+
+	parsedDeniedCIDRs, serviceDeniedCIDRs, err := httpserver.ParseCIDRs(viper.GetStringSlice(deniedCIDRs.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseCIDRs: %s", deniedCIDRs.Arg)
+	}
+
+	parsedTrustedProxies, serviceTrustedProxies, err := httpserver.ParseCIDRs(viper.GetStringSlice(trustedProxies.Arg))
+	if err != nil {
+		return wraperror.Errorf(err, "ParseCIDRs: %s", trustedProxies.Arg)
+	}
+
+	if len(serviceTrustedProxies) > 0 {
+		return wraperror.Errorf(errForPackage, "%s cannot be scoped to a service", trustedProxies.Arg)
+	}
+
 	// Build observers.
 
 	observers := []observer.Observer{}
 
 	// Create object and Serve.
 
-	httpServer := &httpserver.BasicHTTPServer{
-		APIUrlRoutePrefix:         "api",
-		AvoidServing:              viper.GetBool(avoidServe.Arg),
-		EnableAll:                 viper.GetBool(option.EnableAll.Arg),
-		EnableSenzingRestAPI:      viper.GetBool(option.EnableSenzingRestAPI.Arg),
-		EnableSwaggerUI:           viper.GetBool(option.EnableSwaggerUI.Arg),
-		EnableXterm:               viper.GetBool(option.EnableXterm.Arg),
-		GrpcDialOptions:           grpcDialOptions,
-		GrpcTarget:                grpcTarget,
-		LogLevelName:              viper.GetString(option.LogLevel.Arg),
-		ObserverOrigin:            viper.GetString(option.ObserverOrigin.Arg),
-		Observers:                 observers,
-		OpenAPISpecificationRest:  senzingrestservice.OpenAPISpecificationJSON,
-		ReadHeaderTimeout:         ReadHeaderTimeout * time.Second,
-		SenzingSettings:           senzingSettings,
-		SenzingInstanceName:       viper.GetString(option.CoreInstanceName.Arg),
-		SenzingVerboseLogging:     viper.GetInt64(option.CoreLogLevel.Arg),
-		ServerAddress:             viper.GetString(option.ServerAddress.Arg),
-		ServerPort:                viper.GetInt(option.HTTPPort.Arg),
-		SwaggerURLRoutePrefix:     "swagger",
-		TtyOnly:                   viper.GetBool(option.TtyOnly.Arg),
-		XtermAllowedHostnames:     viper.GetStringSlice(option.XtermAllowedHostnames.Arg),
-		XtermArguments:            viper.GetStringSlice(option.XtermArguments.Arg),
-		XtermCommand:              viper.GetString(option.XtermCommand.Arg),
-		XtermConnectionErrorLimit: viper.GetInt(option.XtermConnectionErrorLimit.Arg),
-		XtermKeepalivePingTimeout: viper.GetInt(option.XtermKeepalivePingTimeout.Arg),
-		XtermMaxBufferSizeBytes:   viper.GetInt(option.XtermMaxBufferSizeBytes.Arg),
-		XtermURLRoutePrefix:       "xterm",
+	httpServer, err := httpserver.New(
+		httpserver.WithAddress(viper.GetString(option.ServerAddress.Arg), viper.GetInt(option.HTTPPort.Arg)),
+		httpserver.WithAdminToken(viper.GetString(adminToken.Arg)),
+		httpserver.WithAllowedCIDRs(parsedAllowedCIDRs, serviceAllowedCIDRs),
+		httpserver.WithAPIInstances(parsedAPIInstances...),
+		httpserver.WithAvoidServing(viper.GetBool(avoidServe.Arg)),
+		httpserver.WithCompression(
+			viper.GetBool(enableCompression.Arg),
+			viper.GetInt(compressionMinSize.Arg),
+			viper.GetStringSlice(compressionContentTypes.Arg)...,
+		),
+		httpserver.WithCORS(
+			viper.GetStringSlice(corsAllowedOrigins.Arg),
+			viper.GetStringSlice(corsAllowedMethods.Arg),
+			viper.GetStringSlice(corsAllowedHeaders.Arg),
+			viper.GetBool(corsAllowCredentials.Arg),
+			viper.GetInt(corsMaxAge.Arg),
+		),
+		httpserver.WithDeniedCIDRs(parsedDeniedCIDRs, serviceDeniedCIDRs),
+		httpserver.WithEnableAll(viper.GetBool(option.EnableAll.Arg)),
+		httpserver.WithEnableGrpcWeb(viper.GetBool(enableGrpcWeb.Arg)),
+		httpserver.WithEnableH2C(viper.GetBool(enableH2C.Arg)),
+		httpserver.WithEnableSenzingRestAPI(viper.GetBool(option.EnableSenzingRestAPI.Arg)),
+		httpserver.WithEnableSwaggerUI(viper.GetBool(option.EnableSwaggerUI.Arg)),
+		httpserver.WithEnableXterm(viper.GetBool(option.EnableXterm.Arg)),
+		httpserver.WithGrpc(grpcTarget, grpcDialOptions...),
+		httpserver.WithGrpcTargets(viper.GetString(grpcLoadBalancing.Arg), grpcTargets...),
+		httpserver.WithHSTSMaxAge(viper.GetInt(hstsMaxAge.Arg)),
+		httpserver.WithLogLevelName(viper.GetString(option.LogLevel.Arg)),
+		httpserver.WithLogLevelRevertAfter(time.Duration(viper.GetInt(logLevelRevertAfter.Arg))*time.Second),
+		httpserver.WithMaintenance(
+			viper.GetBool(maintenance.Arg),
+			viper.GetString(maintenanceMessage.Arg),
+			viper.GetInt(maintenanceRetryAfter.Arg),
+			viper.GetBool(maintenanceDisablesXterm.Arg),
+		),
+		httpserver.WithMaxHeaderBytes(viper.GetInt(maxHeaderBytes.Arg)),
+		httpserver.WithMaxRequestBodyBytes(parsedMaxRequestBodyBytes),
+		httpserver.WithMirror(
+			viper.GetString(mirrorURL.Arg),
+			viper.GetInt(mirrorPercent.Arg),
+			viper.GetString(mirrorReportFile.Arg),
+		),
+		httpserver.WithObservers(viper.GetString(option.ObserverOrigin.Arg), observers...),
+		httpserver.WithOpenAPISpecification(senzingrestservice.OpenAPISpecificationJSON),
+		httpserver.WithRateLimits(parsedRateLimits, viper.GetString(rateLimitKeyHeader.Arg)),
+		httpserver.WithReadHeaderTimeout(ReadHeaderTimeout*time.Second),
+		httpserver.WithReverseProxies(parsedReverseProxies...),
+		httpserver.WithSenzing(
+			senzingSettings,
+			viper.GetString(option.CoreInstanceName.Arg),
+			viper.GetInt64(option.CoreLogLevel.Arg),
+		),
+		httpserver.WithStaticCacheMaxAge(viper.GetInt(staticCacheMaxAge.Arg)),
+		httpserver.WithStaticDirectory(
+			viper.GetString(staticDirectory.Arg),
+			viper.GetBool(staticDirectoryExclusive.Arg),
+			viper.GetBool(staticSPAFallback.Arg),
+		),
+		httpserver.WithTemplatesDirectory(viper.GetString(templatesDirectory.Arg)),
+		httpserver.WithTrustedProxies(parsedTrustedProxies),
+		httpserver.WithTtyOnly(viper.GetBool(option.TtyOnly.Arg)),
+		httpserver.WithVersion(Version()),
+		httpserver.WithXterm(
+			viper.GetString(option.XtermCommand.Arg),
+			viper.GetStringSlice(option.XtermArguments.Arg)...,
+		),
+		httpserver.WithXtermAllowedHostnames(viper.GetStringSlice(option.XtermAllowedHostnames.Arg)...),
+		httpserver.WithXtermLimits(
+			viper.GetInt(option.XtermConnectionErrorLimit.Arg),
+			viper.GetInt(option.XtermKeepalivePingTimeout.Arg),
+			viper.GetInt(option.XtermMaxBufferSizeBytes.Arg),
+		),
+	)
+	if err != nil {
+		return wraperror.Errorf(err, "invalid configuration")
 	}
 
 	err = httpServer.Serve(ctx)
@@ -186,6 +635,63 @@ func init() {
 	cmdhelper.Init(RootCmd, ContextVariables)
 }
 
+// Report an error unless two gRPC URLs have the same scheme and query, from which grpcurl.Parse builds dial options.
+func checkSameGrpcDialSettings(firstGrpcURL string, grpcURL string) error {
+	parsedFirstGrpcURL, err := url.Parse(firstGrpcURL)
+	if err != nil {
+		return wraperror.Errorf(err, "url.Parse: %s", firstGrpcURL)
+	}
+
+	parsedGrpcURL, err := url.Parse(grpcURL)
+	if err != nil {
+		return wraperror.Errorf(err, "url.Parse: %s", grpcURL)
+	}
+
+	if parsedGrpcURL.Scheme != parsedFirstGrpcURL.Scheme ||
+		parsedGrpcURL.Query().Encode() != parsedFirstGrpcURL.Query().Encode() {
+		return wraperror.Errorf(
+			errForPackage,
+			"%s and %s differ in scheme or options, but are dialed with the same options",
+			firstGrpcURL,
+			grpcURL,
+		)
+	}
+
+	return nil
+}
+
+// Parse API instances of the form "name=grpcURL".
+func parseAPIInstances(ctx context.Context, specifications []string) ([]httpserver.APIInstance, error) {
+	result := make([]httpserver.APIInstance, 0, len(specifications))
+
+	for _, specification := range specifications {
+		name, grpcURL, found := strings.Cut(specification, "=")
+		if !found {
+			return nil, wraperror.Errorf(
+				errForPackage,
+				"API instance %s is not of the form name=grpcURL",
+				specification,
+			)
+		}
+
+		grpcTarget, grpcDialOptions, err := grpcurl.Parse(ctx, strings.TrimSpace(grpcURL))
+		if err != nil {
+			return nil, wraperror.Errorf(err, "grpcurl.Parse: %s", grpcURL)
+		}
+
+		result = append(result, httpserver.APIInstance{
+			GrpcDialOptions:       grpcDialOptions,
+			GrpcTarget:            grpcTarget,
+			Name:                  strings.TrimSpace(name),
+			SenzingInstanceName:   "",
+			SenzingSettings:       "",
+			SenzingVerboseLogging: 0,
+		})
+	}
+
+	return result, nil
+}
+
 // --- Networking -------------------------------------------------------------
 
 func getOutboundIP() net.IP {
@@ -0,0 +1,120 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The ParseCIDRs function parses a list of CIDRs, some of which may be scoped to a service.
+
+Input
+  - specifications: Values like "10.0.0.0/8", "192.0.2.7", or "xterm=10.0.0.0/8".
+    A bare IP address is treated as a single-address prefix.
+
+Output
+  - The prefixes that apply to all services.
+  - The prefixes that apply to individual services, keyed by service name.
+*/
+func ParseCIDRs(specifications []string) ([]netip.Prefix, map[string][]netip.Prefix, error) {
+	var global []netip.Prefix
+
+	perService := map[string][]netip.Prefix{}
+
+	for _, specification := range specifications {
+		serviceName, cidr, isServiceScoped := strings.Cut(strings.TrimSpace(specification), "=")
+		if !isServiceScoped {
+			cidr = serviceName
+		}
+
+		prefix, err := parseCIDR(cidr)
+		if err != nil {
+			return nil, nil, wraperror.Errorf(err, "invalid CIDR %q", specification)
+		}
+
+		if isServiceScoped {
+			perService[serviceName] = append(perService[serviceName], prefix)
+		} else {
+			global = append(global, prefix)
+		}
+	}
+
+	return global, perService, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func parseCIDR(cidr string) (netip.Prefix, error) {
+	if strings.Contains(cidr, "/") {
+		prefix, err := netip.ParsePrefix(cidr)
+
+		return prefix.Masked(), wraperror.Errorf(err, "ParsePrefix")
+	}
+
+	address, err := netip.ParseAddr(cidr)
+	if err != nil {
+		return netip.Prefix{}, wraperror.Errorf(err, "ParseAddr")
+	}
+
+	return netip.PrefixFrom(address, address.BitLen()), nil
+}
+
+func prefixesContain(prefixes []netip.Prefix, address netip.Addr) bool {
+	return slices.ContainsFunc(prefixes, func(prefix netip.Prefix) bool {
+		return prefix.Contains(address)
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that only permitted client IP addresses reach it.
+// Denied CIDRs always win.  A service's allowed CIDRs replace the global allowed CIDRs.
+func (httpServer *BasicHTTPServer) accessControlHandler(serviceName string, next http.Handler) http.Handler {
+	_, serviceDeniedCIDRs, _ := lookupRoute(httpServer.ServiceDeniedCIDRs, serviceName)
+	deniedCIDRs := slices.Concat(httpServer.DeniedCIDRs, serviceDeniedCIDRs)
+
+	_, allowedCIDRs, found := lookupRoute(httpServer.ServiceAllowedCIDRs, serviceName)
+	if !found {
+		allowedCIDRs = httpServer.AllowedCIDRs
+	}
+
+	if len(deniedCIDRs) == 0 && len(allowedCIDRs) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		address, err := netip.ParseAddr(clientIP(request))
+		address = address.Unmap().WithZone("")
+		isDenied := err != nil ||
+			prefixesContain(deniedCIDRs, address) ||
+			(len(allowedCIDRs) > 0 && !prefixesContain(allowedCIDRs, address))
+
+		if isDenied {
+			httpServer.logger.WarnContext(
+				request.Context(),
+				"client denied by CIDR rules",
+				"service", serviceName,
+				"client", request.RemoteAddr,
+				"method", request.Method,
+				"path", request.URL.Path,
+			)
+			writeProblem(writer, request, http.StatusForbidden, "")
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// The route prefix of the admin API.
+const routePrefixAdmin = "admin"
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that only requests bearing AdminToken are served.
+func (httpServer *BasicHTTPServer) adminAuthHandler(next http.Handler) http.Handler {
+	// Hashed, so that the comparison takes the same time whatever the length of the token offered.
+	adminToken := sha256.Sum256([]byte(httpServer.AdminToken))
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token, isBearer := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		offeredToken := sha256.Sum256([]byte(token))
+
+		if !isBearer || subtle.ConstantTimeCompare(offeredToken[:], adminToken[:]) != 1 {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeProblem(writer, request, http.StatusUnauthorized, "admin API requires the admin token")
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// The admin API changes the running server.  Every request must bear AdminToken.
+func (httpServer *BasicHTTPServer) getAdminMux(ctx context.Context) *http.ServeMux {
+	_ = ctx
+
+	submux := http.NewServeMux()
+	submux.HandleFunc("GET /log-level", httpServer.logLevelSwitch.statusFunc)
+	submux.HandleFunc("PUT /log-level", httpServer.logLevelSwitch.statusFunc)
+	submux.HandleFunc("GET /maintenance", httpServer.maintenance.statusFunc)
+	submux.HandleFunc("PUT /maintenance", httpServer.maintenance.statusFunc)
+	submux.HandleFunc("GET /services", httpServer.serviceSwitches.statusesFunc)
+	submux.HandleFunc("PUT /services/{name...}", httpServer.serviceSwitches.statusFunc)
+
+	if httpServer.rateLimiter != nil {
+		submux.HandleFunc("GET /ratelimits", httpServer.rateLimiter.statusFunc)
+	}
+
+	return submux
+}
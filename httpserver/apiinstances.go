@@ -0,0 +1,138 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+	"github.com/senzing-garage/go-rest-api-service/senzingrestservice"
+	"google.golang.org/grpc"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+/*
+APIInstance describes an additional Senzing REST API for its own Senzing repository,
+mounted at "/api/{Name}/" with its OpenAPI specification at "/api/{Name}/swagger_spec".
+Its service is named "api/{Name}", so per-service settings fall back to those of "api".
+The local Senzing engine is initialized once for the whole process, so only one Senzing REST API,
+the main one or an instance, can use SenzingSettings.  The others need a GrpcTarget.
+*/
+type APIInstance struct {
+	GrpcDialOptions       []grpc.DialOption
+	GrpcTarget            string // If empty, SenzingSettings are used with the local Senzing engine.
+	Name                  string // e.g. "sales".
+	SenzingInstanceName   string // If empty, the Name.
+	SenzingSettings       string
+	SenzingVerboseLogging int64
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+/*
+The first path segments of the routes of the Senzing REST API, which API instances mounted beside it
+must not shadow: those of the OpenAPI specification its routes are generated from, and "swagger_spec".
+*/
+func apiPathSegments() ([]string, error) {
+	var specification struct {
+		Paths map[string]json.RawMessage `json:"paths"`
+	}
+
+	err := json.Unmarshal(senzingrestservice.OpenAPISpecificationJSON, &specification)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "Unmarshal OpenAPISpecificationJSON")
+	}
+
+	result := []string{"swagger_spec"}
+
+	for apiPath := range specification.Paths {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(apiPath, "/"), "/")
+		if len(segment) > 0 {
+			result = append(result, segment)
+		}
+	}
+
+	return result, nil
+}
+
+// The OpenAPI specification template of a Senzing REST API mounted at routePrefix instead of apiURLRoutePrefix.
+func apiInstanceSpecification(specification []byte, apiURLRoutePrefix string, routePrefix string) []byte {
+	return bytes.ReplaceAll(
+		specification,
+		[]byte("{{.RequestHost}}/"+apiURLRoutePrefix+`"`),
+		[]byte("{{.RequestHost}}/"+routePrefix+`"`),
+	)
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+func (httpServer *BasicHTTPServer) apiInstanceRoutePrefix(instance APIInstance) string {
+	return httpServer.APIUrlRoutePrefix + "/" + instance.Name
+}
+
+// The Senzing REST API of an instance, with its own OpenAPI specification.
+func (httpServer *BasicHTTPServer) getAPIInstanceMux(
+	ctx context.Context,
+	instance APIInstance,
+) (*http.ServeMux, error) {
+	routePrefix := httpServer.apiInstanceRoutePrefix(instance)
+
+	if len(instance.SenzingInstanceName) == 0 {
+		instance.SenzingInstanceName = instance.Name
+	}
+
+	apiServer, err := httpServer.newSenzingAPIServer(ctx, instance, routePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	specification := apiInstanceSpecification(
+		httpServer.OpenAPISpecificationRest,
+		httpServer.APIUrlRoutePrefix,
+		routePrefix,
+	)
+
+	openAPIFunc, err := httpServer.openAPIFunc(ctx, specification)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "openAPIFunc")
+	}
+
+	submux := http.NewServeMux()
+	submux.Handle("/", apiServer)
+	submux.HandleFunc("/swagger_spec", openAPIFunc)
+
+	return submux, nil
+}
+
+// The services of APIInstances, in the order they are configured.
+func (httpServer *BasicHTTPServer) getAPIInstanceServices() []Service {
+	result := make([]Service, 0, len(httpServer.APIInstances))
+
+	for _, instance := range httpServer.APIInstances {
+		result = append(result, &basicService{
+			bannerName: "Senzing REST API (" + instance.Name + ")",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--api-instances",
+				EnvironmentVariable: "SENZING_TOOLS_API_INSTANCES",
+				Title:               "Senzing API Server (" + instance.Name + ")",
+			},
+			handler: func(ctx context.Context) (http.Handler, error) {
+				return httpServer.getAPIInstanceMux(ctx, instance)
+			},
+			isEnabled:   httpServer.EnableAll || httpServer.EnableSenzingRestAPI,
+			name:        ServiceNameAPI + "/" + instance.Name,
+			routePrefix: httpServer.apiInstanceRoutePrefix(instance),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,194 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+type bodyLimitReader struct {
+	body     io.ReadCloser
+	exceeded bool
+}
+
+// If the handler read past the limit, its response is replaced by a 413.
+type bodyLimitResponseWriter struct {
+	http.ResponseWriter
+
+	body        *bodyLimitReader
+	limit       int64
+	replaced    bool
+	request     *http.Request
+	wroteHeader bool
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	kibibyte = 1 << 10
+	mebibyte = 1 << 20
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// Record-loading requests are "api/write".  The console and its neighbors only need small forms.
+var defaultMaxRequestBodyBytes = map[string]int64{
+	ServiceNameAdmin:                       64 * kibibyte,
+	ServiceNameAPI:                         1 * mebibyte,
+	ServiceNameAPI + "/" + RouteClassWrite: 64 * mebibyte,
+	ServiceNameConsole:                     64 * kibibyte,
+	ServiceNameGrpcWeb:                     6 * mebibyte, // A 4 MiB gRPC message, base64-encoded by grpc-web-text.
+	ServiceNameSwagger:                     64 * kibibyte,
+	ServiceNameXterm:                       64 * kibibyte,
+}
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The ParseMaxRequestBodyBytes function parses request body limits of the form "name=bytes".
+
+Input
+  - specifications: Values like "console=65536" or "api/write=134217728".
+    A limit of 0 removes the limit for that name.
+
+Output
+  - A map of byte limits keyed by name.
+*/
+func ParseMaxRequestBodyBytes(specifications []string) (map[string]int64, error) {
+	result := map[string]int64{}
+
+	for _, specification := range specifications {
+		name, value, found := strings.Cut(specification, "=")
+		if !found || len(name) == 0 {
+			return nil, wraperror.Errorf(errForPackage, "body limit %q is not of the form name=bytes", specification)
+		}
+
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || limit < 0 {
+			return nil, wraperror.Errorf(errForPackage, "body limit %q has an invalid number of bytes", specification)
+		}
+
+		result[strings.TrimSpace(name)] = limit
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func writeBodyTooLarge(writer http.ResponseWriter, request *http.Request, limit int64) {
+	writer.Header().Set("Connection", "close")
+	writeProblem(writer, request, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit))
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that request bodies larger than the route's limit are refused with a 413.
+func (httpServer *BasicHTTPServer) bodyLimitHandler(serviceName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		limit := httpServer.getMaxRequestBodyBytes(routeName(serviceName, request))
+		if limit <= 0 || request.Body == nil || request.Body == http.NoBody {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		if request.ContentLength > limit {
+			writeBodyTooLarge(writer, request, limit)
+
+			return
+		}
+
+		body := &bodyLimitReader{
+			body:     http.MaxBytesReader(writer, request.Body, limit),
+			exceeded: false,
+		}
+		request.Body = body
+		next.ServeHTTP(&bodyLimitResponseWriter{
+			ResponseWriter: writer,
+			body:           body,
+			limit:          limit,
+			replaced:       false,
+			request:        request,
+			wroteHeader:    false,
+		}, request)
+	})
+}
+
+func (httpServer *BasicHTTPServer) getMaxRequestBodyBytes(route string) int64 {
+	_, limit, found := lookupRoute(httpServer.MaxRequestBodyBytes, route)
+	if found {
+		return limit
+	}
+
+	_, limit, _ = lookupRoute(defaultMaxRequestBodyBytes, route)
+
+	return limit
+}
+
+func (reader *bodyLimitReader) Close() error {
+	return wraperror.Errorf(reader.body.Close(), wraperror.NoMessage)
+}
+
+func (reader *bodyLimitReader) Read(buffer []byte) (int, error) {
+	count, err := reader.body.Read(buffer)
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		reader.exceeded = true
+	}
+
+	return count, err //nolint:wrapcheck // io.EOF must reach the caller unwrapped.
+}
+
+func (writer *bodyLimitResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *bodyLimitResponseWriter) Write(data []byte) (int, error) {
+	if !writer.wroteHeader {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	if writer.replaced {
+		return len(data), nil
+	}
+
+	return writer.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+func (writer *bodyLimitResponseWriter) WriteHeader(statusCode int) {
+	if writer.wroteHeader {
+		return
+	}
+
+	writer.wroteHeader = true
+
+	if writer.body.exceeded {
+		writer.replaced = true
+		writeBodyTooLarge(writer.ResponseWriter, writer.request, writer.limit)
+
+		return
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
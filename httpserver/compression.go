@@ -0,0 +1,313 @@
+package httpserver
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(writer io.Writer)
+}
+
+// Buffers the start of a response until it is known whether it is worth compressing.
+type compressionResponseWriter struct {
+	http.ResponseWriter
+
+	buffer       []byte
+	compressor   compressor
+	contentTypes []string
+	decided      bool
+	encoding     string
+	minSize      int
+	statusCode   int
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	compressionDefaultMinSize = 1024
+	encodingBrotli            = "br"
+	encodingGzip              = "gzip"
+	encodingZstd              = "zstd"
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+var defaultCompressionContentTypes = []string{
+	"application/javascript",
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"image/svg+xml",
+	"text/css",
+	"text/html",
+	"text/javascript",
+	"text/plain",
+	"text/xml",
+}
+
+// In order of preference when a client accepts several equally.
+var compressionEncodings = []string{encodingBrotli, encodingZstd, encodingGzip}
+
+var compressorPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}},
+	encodingGzip: {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	encodingZstd: {New: func() any {
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+
+		return encoder
+	}},
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Choose the encoding the client prefers from the Accept-Encoding header, or "" for none.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := parseQualityValues(acceptEncoding)
+	result := ""
+	bestQuality := 0.0
+
+	for _, encoding := range compressionEncodings {
+		quality, found := qualities[encoding]
+		if !found {
+			quality, found = qualities["*"]
+		}
+
+		if found && quality > bestQuality {
+			result = encoding
+			bestQuality = quality
+		}
+	}
+
+	return result
+}
+
+// Parse a header like Accept or Accept-Encoding into the quality of each, lower case, value.
+func parseQualityValues(header string) map[string]float64 {
+	result := map[string]float64{}
+
+	for element := range strings.SplitSeq(header, ",") {
+		value, parameters, _ := strings.Cut(element, ";")
+		quality := 1.0
+
+		for parameter := range strings.SplitSeq(parameters, ";") {
+			qualityValue, found := strings.CutPrefix(strings.TrimSpace(parameter), "q=")
+			if !found {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(qualityValue, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+
+		value = strings.ToLower(strings.TrimSpace(value))
+		if len(value) > 0 {
+			result[value] = quality
+		}
+	}
+
+	return result
+}
+
+func isWebsocketUpgrade(request *http.Request) bool {
+	return strings.EqualFold(request.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(strings.ToLower(request.Header.Get("Connection")), "upgrade")
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that responses are compressed with the encoding the client prefers.
+func (httpServer *BasicHTTPServer) compressionHandler(next http.Handler) http.Handler {
+	if !httpServer.EnableCompression {
+		return next
+	}
+
+	minSize := httpServer.CompressionMinSize
+	if minSize <= 0 {
+		minSize = compressionDefaultMinSize
+	}
+
+	contentTypes := httpServer.CompressionContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressionContentTypes
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if isWebsocketUpgrade(request) {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(request.Header.Get("Accept-Encoding"))
+		if len(encoding) == 0 || request.Method == http.MethodHead || len(request.Header.Get("Range")) > 0 {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		compressionWriter := &compressionResponseWriter{
+			ResponseWriter: writer,
+			buffer:         nil,
+			compressor:     nil,
+			contentTypes:   contentTypes,
+			decided:        false,
+			encoding:       encoding,
+			minSize:        minSize,
+			statusCode:     http.StatusOK,
+		}
+
+		// Not deferred: while a panic unwinds, nothing may be sent, so that recoveryHandler can still send a 500.
+		next.ServeHTTP(compressionWriter, request)
+		compressionWriter.close()
+	})
+}
+
+func (writer *compressionResponseWriter) Flush() {
+	if !writer.decided {
+		writer.decide(true)
+	}
+
+	if writer.compressor != nil {
+		_ = writer.compressor.Flush()
+	}
+
+	_ = http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+func (writer *compressionResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *compressionResponseWriter) Write(data []byte) (int, error) {
+	if writer.decided {
+		if writer.compressor != nil {
+			return writer.compressor.Write(data) //nolint:wrapcheck
+		}
+
+		return writer.ResponseWriter.Write(data) //nolint:wrapcheck
+	}
+
+	writer.buffer = append(writer.buffer, data...)
+	if len(writer.buffer) >= writer.minSize {
+		writer.decide(true)
+	}
+
+	return len(data), nil
+}
+
+func (writer *compressionResponseWriter) WriteHeader(statusCode int) {
+	if statusCode < http.StatusOK {
+		writer.ResponseWriter.WriteHeader(statusCode)
+
+		return
+	}
+
+	writer.statusCode = statusCode
+}
+
+// Release the compressor and write anything still buffered.
+func (writer *compressionResponseWriter) close() {
+	if !writer.decided {
+		writer.decide(len(writer.buffer) >= writer.minSize)
+	}
+
+	if writer.compressor != nil {
+		_ = writer.compressor.Close()
+		writer.compressor.Reset(io.Discard)
+		compressorPools[writer.encoding].Put(writer.compressor)
+		writer.compressor = nil
+	}
+}
+
+// Commit to compressing, or not, then send the status line and whatever was buffered.
+func (writer *compressionResponseWriter) decide(isLargeEnough bool) {
+	writer.decided = true
+	header := writer.Header()
+
+	if isLargeEnough && writer.shouldCompress() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", writer.encoding)
+
+		etag := header.Get("Etag")
+		if len(etag) > 0 && !strings.HasPrefix(etag, "W/") {
+			header.Set("Etag", "W/"+etag)
+		}
+
+		pooledCompressor, isCompressor := compressorPools[writer.encoding].Get().(compressor)
+		if isCompressor {
+			writer.compressor = pooledCompressor
+			writer.compressor.Reset(writer.ResponseWriter)
+		} else {
+			header.Del("Content-Encoding")
+		}
+	}
+
+	writer.ResponseWriter.WriteHeader(writer.statusCode)
+
+	if len(writer.buffer) > 0 {
+		if writer.compressor != nil {
+			_, _ = writer.compressor.Write(writer.buffer)
+		} else {
+			_, _ = writer.ResponseWriter.Write(writer.buffer)
+		}
+	}
+
+	writer.buffer = nil
+}
+
+func (writer *compressionResponseWriter) shouldCompress() bool {
+	header := writer.Header()
+
+	switch {
+	case writer.statusCode == http.StatusNoContent, writer.statusCode == http.StatusNotModified:
+		return false
+	case len(header.Get("Content-Encoding")) > 0:
+		return false
+	}
+
+	// Sniff now, as net/http would otherwise sniff the compressed bytes.
+	contentType := header.Get("Content-Type")
+	if len(contentType) == 0 && len(writer.buffer) > 0 {
+		contentType = http.DetectContentType(writer.buffer)
+		header.Set("Content-Type", contentType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(writer.contentTypes, mediaType)
+}
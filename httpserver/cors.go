@@ -0,0 +1,166 @@
+package httpserver
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+type corsPolicy struct {
+	allowCredentials bool
+	allowedHeaders   string
+	allowedMethods   string
+	allowedOrigins   []string
+	maxAge           string
+}
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+var (
+	corsDefaultAllowedHeaders = []string{"Accept", "Authorization", "Content-Type"}
+	corsDefaultAllowedMethods = []string{
+		http.MethodDelete,
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+		http.MethodPost,
+		http.MethodPut,
+	}
+)
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// The requiredHeaders are allowed in addition to the configured or default headers.
+func newCORSPolicy(httpServer *BasicHTTPServer, requiredHeaders ...string) *corsPolicy {
+	if len(httpServer.CORSAllowedOrigins) == 0 {
+		return nil
+	}
+
+	allowedHeaders := httpServer.CORSAllowedHeaders
+	if len(allowedHeaders) == 0 {
+		allowedHeaders = corsDefaultAllowedHeaders
+	}
+
+	allowedHeaders = append(slices.Clip(allowedHeaders), requiredHeaders...)
+
+	allowedMethods := httpServer.CORSAllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = corsDefaultAllowedMethods
+	}
+
+	maxAge := ""
+	if httpServer.CORSMaxAge > 0 {
+		maxAge = strconv.Itoa(httpServer.CORSMaxAge)
+	}
+
+	return &corsPolicy{
+		allowCredentials: httpServer.CORSAllowCredentials,
+		allowedHeaders:   strings.Join(allowedHeaders, ", "),
+		allowedMethods:   strings.ToUpper(strings.Join(allowedMethods, ", ")),
+		allowedOrigins:   httpServer.CORSAllowedOrigins,
+		maxAge:           maxAge,
+	}
+}
+
+// Match an origin against "*", an exact origin, or a wildcard like "https://*.example.com".
+func corsOriginMatches(pattern string, origin string) bool {
+	if pattern == "*" || strings.EqualFold(pattern, origin) {
+		return true
+	}
+
+	prefix, suffix, found := strings.Cut(pattern, "*")
+	if !found {
+		return false
+	}
+
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+		strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix))
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that cross-origin requests are answered per the policy.
+// Preflight requests are answered here and never reach the wrapped handler.
+func (policy *corsPolicy) handler(next http.Handler) http.Handler {
+	if policy == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Add("Vary", "Origin")
+
+		origin := request.Header.Get("Origin")
+		isPreflight := request.Method == http.MethodOptions &&
+			len(request.Header.Get("Access-Control-Request-Method")) > 0
+
+		if len(origin) == 0 {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		if !policy.isAllowedOrigin(origin) {
+			if isPreflight {
+				writeProblem(writer, request, http.StatusForbidden, "origin not allowed")
+
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		policy.setAllowOrigin(writer, origin)
+
+		if isPreflight {
+			writer.Header().Add("Vary", "Access-Control-Request-Method")
+			writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			writer.Header().Set("Access-Control-Allow-Methods", policy.allowedMethods)
+			writer.Header().Set("Access-Control-Allow-Headers", policy.allowedHeaders)
+
+			if len(policy.maxAge) > 0 {
+				writer.Header().Set("Access-Control-Max-Age", policy.maxAge)
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (policy *corsPolicy) isAllowedOrigin(origin string) bool {
+	return slices.ContainsFunc(policy.allowedOrigins, func(pattern string) bool {
+		return corsOriginMatches(pattern, origin)
+	})
+}
+
+// "*" is never combined with credentials, which would let any site make credentialed reads.  See Validate.
+func (policy *corsPolicy) setAllowOrigin(writer http.ResponseWriter, origin string) {
+	if slices.Contains(policy.allowedOrigins, "*") {
+		writer.Header().Set("Access-Control-Allow-Origin", "*")
+
+		return
+	}
+
+	writer.Header().Set("Access-Control-Allow-Origin", origin)
+
+	if policy.allowCredentials {
+		writer.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
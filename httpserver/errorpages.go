@@ -0,0 +1,248 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+type errorPageKey struct{}
+
+// Holds back an error response, so that it can be replaced by one in the format the client prefers.
+type errorPageResponseWriter struct {
+	http.ResponseWriter
+
+	body             []byte
+	isPassingThrough bool // A service's own handler, such as a reverse proxy, is responding.
+	isProblem        bool // The response is one of this package's problem+json errors.
+	isReplacing      bool
+	prefersHTML      bool
+	statusCode       int
+	wroteHeader      bool
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	errorPageMaxBodyBytes = 4096
+	errorPageTemplatePath = "/errors/error.html"
+)
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// The script and style nonce permitted by a Content-Security-Policy header, if any.
+func cspNonceFromHeader(header http.Header) string {
+	_, nonce, found := strings.Cut(header.Get("Content-Security-Policy"), "'nonce-")
+	if !found {
+		return ""
+	}
+
+	nonce, _, _ = strings.Cut(nonce, "'")
+
+	return nonce
+}
+
+// Record that the response is one of this package's problem+json errors, which are replaced even when passing through.
+func markErrorPageProblem(ctx context.Context) {
+	errorPageWriter, found := ctx.Value(errorPageKey{}).(*errorPageResponseWriter)
+	if found {
+		errorPageWriter.isProblem = true
+	}
+}
+
+// Report whether a client, such as a browser, asks for HTML ahead of JSON.
+func prefersHTML(request *http.Request) bool {
+	qualities := parseQualityValues(request.Header.Get("Accept"))
+	htmlQuality := max(qualities["text/html"], qualities["text/*"])
+	jsonQuality := max(
+		qualities["application/json"],
+		qualities["application/problem+json"],
+		qualities["application/*"],
+		qualities["*/*"],
+	)
+
+	return htmlQuality > 0 && htmlQuality >= jsonQuality
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+/*
+Wrap a handler so that plain error responses, such as the file server's 404 and the problem+json errors
+of this package, are rendered as the Console's error page for browsers and as problem+json for other clients.
+Error responses of other types, and those of services other than the Console, such as the Senzing REST API and
+reverse proxies, are passed through.  See errorPagesPassThroughHandler.
+*/
+func (httpServer *BasicHTTPServer) errorPagesHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		errorPageWriter := &errorPageResponseWriter{
+			ResponseWriter:   writer,
+			body:             nil,
+			isPassingThrough: false,
+			isProblem:        false,
+			isReplacing:      false,
+			prefersHTML:      prefersHTML(request),
+			statusCode:       http.StatusOK,
+			wroteHeader:      false,
+		}
+		next.ServeHTTP(errorPageWriter, request.WithContext(
+			context.WithValue(request.Context(), errorPageKey{}, errorPageWriter),
+		))
+
+		if errorPageWriter.isReplacing {
+			httpServer.writeErrorPage(writer, request, errorPageWriter)
+		}
+	})
+}
+
+// Wrap a service's own handler, so that its error responses are not replaced.  Those of the middleware are.
+func errorPagesPassThroughHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		errorPageWriter, found := request.Context().Value(errorPageKey{}).(*errorPageResponseWriter)
+		if found {
+			errorPageWriter.isPassingThrough = true
+
+			// After a panic, the recovered 500 is the server's own.
+			defer func() {
+				errorPageWriter.isPassingThrough = false
+			}()
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Respond with the error page or problem+json in place of the held back error response.
+func (httpServer *BasicHTTPServer) writeErrorPage(
+	writer http.ResponseWriter,
+	request *http.Request,
+	errorPageWriter *errorPageResponseWriter,
+) {
+	var problem problemDetails
+
+	_ = json.Unmarshal(errorPageWriter.body, &problem)
+
+	if !errorPageWriter.prefersHTML {
+		writeProblem(writer, request, errorPageWriter.statusCode, problem.Detail)
+
+		return
+	}
+
+	templateVariables := TemplateVariables{
+		CSPNonce:    cspNonceFromHeader(writer.Header()),
+		ErrorDetail: problem.Detail,
+		ErrorStatus: errorPageWriter.statusCode,
+		ErrorTitle:  http.StatusText(errorPageWriter.statusCode),
+		HTMLTitle:   "Senzing Tools",
+		RequestID:   RequestID(request.Context()),
+	}
+
+	var bytesBuffer bytes.Buffer
+
+	errorTemplate, found := httpServer.siteTemplates[errorPageTemplatePath]
+	if !found || errorTemplate.Execute(&bytesBuffer, templateVariables) != nil {
+		writeProblem(writer, request, errorPageWriter.statusCode, problem.Detail)
+
+		return
+	}
+
+	header := writer.Header()
+	header.Del("Content-Length")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(errorPageWriter.statusCode)
+	_, _ = writer.Write(bytesBuffer.Bytes())
+}
+
+func (writer *errorPageResponseWriter) Flush() {
+	if !writer.isReplacing {
+		_ = http.NewResponseController(writer.ResponseWriter).Flush()
+	}
+}
+
+// Websocket upgrades, like those of the web terminal, need the connection.
+func (writer *errorPageResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	writer.wroteHeader = true
+	connection, readWriter, err := http.NewResponseController(writer.ResponseWriter).Hijack()
+
+	return connection, readWriter, wraperror.Errorf(err, "Hijack")
+}
+
+func (writer *errorPageResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *errorPageResponseWriter) Write(data []byte) (int, error) {
+	if !writer.wroteHeader {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	if writer.isReplacing {
+		if len(writer.body) < errorPageMaxBodyBytes {
+			writer.body = append(writer.body, data...)
+		}
+
+		return len(data), nil
+	}
+
+	return writer.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+func (writer *errorPageResponseWriter) WriteHeader(statusCode int) {
+	if writer.wroteHeader {
+		return
+	}
+
+	if statusCode < http.StatusOK {
+		writer.ResponseWriter.WriteHeader(statusCode)
+
+		return
+	}
+
+	writer.wroteHeader = true
+	writer.statusCode = statusCode
+
+	if statusCode >= http.StatusBadRequest && writer.isReplaceable() {
+		writer.isReplacing = true
+
+		return
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Only uncompressed plain text and problem+json errors are replaced, and problem+json only for browsers.
+// While passing through, only this package's problem+json errors are.
+func (writer *errorPageResponseWriter) isReplaceable() bool {
+	header := writer.Header()
+	if len(header.Get("Content-Encoding")) > 0 || (writer.isPassingThrough && !writer.isProblem) {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+
+	switch mediaType {
+	case "text/plain":
+		return true
+	case "application/problem+json":
+		return writer.prefersHTML
+	default:
+		return false
+	}
+}
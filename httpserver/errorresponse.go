@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// An RFC 7807 problem details object.
+type problemDetails struct {
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Status    int    `json:"status"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Respond with an application/problem+json body describing an error.
+func writeProblem(writer http.ResponseWriter, request *http.Request, statusCode int, detail string) {
+	header := writer.Header()
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	header.Del("Etag")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Content-Type", "application/problem+json")
+	header.Set("X-Content-Type-Options", "nosniff")
+	markErrorPageProblem(request.Context())
+	writer.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(writer).Encode(problemDetails{
+		Detail:    detail,
+		Instance:  request.URL.Path,
+		RequestID: RequestID(request.Context()),
+		Status:    statusCode,
+		Title:     http.StatusText(statusCode),
+		Type:      "about:blank",
+	})
+}
@@ -0,0 +1,346 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/balancer/leastrequest" // Registers the least_request_experimental policy.
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// GrpcTargetStatus is a row of the Console overview's Senzing gRPC targets.
+type GrpcTargetStatus struct {
+	Failures  int    // Consecutive failed health checks.
+	LastError string // Of the last failed health check.
+	State     string // "serving", "ejected", or "unknown" before the first health check.
+	Status    string // "green" if serving, "red" if ejected, otherwise "orange".
+	Target    string
+}
+
+/*
+Balances gRPC calls across GrpcTargets.  It is a resolver for its own scheme,
+resolving to the targets that pass health checks, so that every connection dialed with its
+dial options, such as those of the Senzing REST API and the gRPC-Web gateway, follows ejections.
+*/
+type grpcBalancer struct {
+	loadBalancing     string
+	mutex             sync.Mutex
+	resolvers         map[*grpcBalancerResolver]bool
+	targetDialOptions []grpc.DialOption
+	targets           []*grpcBalancerTarget
+}
+
+// A resolver built for one connection.
+type grpcBalancerResolver struct {
+	balancer   *grpcBalancer
+	clientConn resolver.ClientConn
+}
+
+type grpcBalancerTarget struct {
+	failures  int
+	lastError string
+	state     string
+	target    string
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// Values of GrpcLoadBalancing.
+const (
+	GrpcLoadBalancingLeastRequest = "least_request"
+	GrpcLoadBalancingRoundRobin   = "round_robin"
+)
+
+const (
+	grpcBalancerScheme           = "senzing-balancer"
+	grpcHealthCheckEjectAfter    = 3 // Consecutive failed health checks, so that one lost check does not eject.
+	grpcHealthCheckInterval      = 5 * time.Second
+	grpcHealthCheckMaxBackoff    = time.Minute
+	grpcHealthCheckTimeout       = 2 * time.Second
+	grpcHealthCheckMaxDoublings  = 4
+	grpcHealthCheckRetryInterval = time.Second
+	grpcTargetStateEjected       = "ejected"
+	grpcTargetStateServing       = "serving"
+	grpcTargetStateUnknown       = "unknown"
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// The gRPC load balancing policies of the values of GrpcLoadBalancing.
+var grpcLoadBalancingPolicies = map[string]string{
+	"":                            "round_robin",
+	GrpcLoadBalancingLeastRequest: "least_request_experimental",
+	GrpcLoadBalancingRoundRobin:   "round_robin",
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Servers without the gRPC health service are healthy if they answer at all.
+func checkGrpcHealth(ctx context.Context, healthClient grpc_health_v1.HealthClient) error {
+	ctx, cancel := context.WithTimeout(ctx, grpcHealthCheckTimeout)
+	defer cancel()
+
+	response, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: ""})
+
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return nil
+	case err != nil:
+		return wraperror.Errorf(err, "Check")
+	case response.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING:
+		return wraperror.Errorf(errForPackage, "health status is %s", response.GetStatus())
+	default:
+		return nil
+	}
+}
+
+// The delay before the next health check of a target.  A failure is confirmed quickly, before the target is ejected.
+// Ejected targets back off exponentially.
+func grpcHealthCheckDelay(failures int) time.Duration {
+	if failures > 0 && failures < grpcHealthCheckEjectAfter {
+		return grpcHealthCheckRetryInterval
+	}
+
+	doublings := min(max(failures-grpcHealthCheckEjectAfter, 0), grpcHealthCheckMaxDoublings)
+
+	return min(grpcHealthCheckInterval<<doublings, grpcHealthCheckMaxBackoff)
+}
+
+func newGrpcBalancer(targets []string, loadBalancing string, targetDialOptions []grpc.DialOption) *grpcBalancer {
+	result := &grpcBalancer{
+		loadBalancing:     loadBalancing,
+		mutex:             sync.Mutex{},
+		resolvers:         map[*grpcBalancerResolver]bool{},
+		targetDialOptions: targetDialOptions,
+		targets:           make([]*grpcBalancerTarget, 0, len(targets)),
+	}
+
+	for _, target := range targets {
+		result.targets = append(result.targets, &grpcBalancerTarget{
+			failures:  0,
+			lastError: "",
+			state:     grpcTargetStateUnknown,
+			target:    target,
+		})
+	}
+
+	return result
+}
+
+// ----------------------------------------------------------------------------
+// Public methods
+// ----------------------------------------------------------------------------
+
+func (balancer *grpcBalancer) Build(
+	target resolver.Target,
+	clientConn resolver.ClientConn,
+	options resolver.BuildOptions,
+) (resolver.Resolver, error) {
+	_ = target
+	_ = options
+	result := &grpcBalancerResolver{
+		balancer:   balancer,
+		clientConn: clientConn,
+	}
+
+	balancer.mutex.Lock()
+	balancer.resolvers[result] = true
+	state := balancer.resolverState()
+	balancer.mutex.Unlock()
+
+	err := clientConn.UpdateState(state)
+	if err != nil {
+		clientConn.ReportError(err)
+	}
+
+	return result, nil
+}
+
+func (balancer *grpcBalancer) Scheme() string {
+	return grpcBalancerScheme
+}
+
+func (grpcResolver *grpcBalancerResolver) Close() {
+	grpcResolver.balancer.mutex.Lock()
+	defer grpcResolver.balancer.mutex.Unlock()
+
+	delete(grpcResolver.balancer.resolvers, grpcResolver)
+}
+
+func (grpcResolver *grpcBalancerResolver) ResolveNow(options resolver.ResolveNowOptions) {
+	_ = options
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Dial options that resolve the target of the balancer and balance across its addresses.
+func (balancer *grpcBalancer) dialOptions() []grpc.DialOption {
+	return append(
+		slices.Clip(balancer.targetDialOptions),
+		grpc.WithResolvers(balancer),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(
+			`{"loadBalancingConfig": [{%q: {}}]}`,
+			grpcLoadBalancingPolicies[balancer.loadBalancing],
+		)),
+	)
+}
+
+// The addresses of the targets that are not ejected.  If every target is ejected, all of them are tried.
+// Callers hold the mutex.
+func (balancer *grpcBalancer) resolverState() resolver.State {
+	var addresses, allAddresses []resolver.Address
+
+	for _, target := range balancer.targets {
+		serverName, _, err := net.SplitHostPort(target.target)
+		if err != nil {
+			serverName = target.target
+		}
+
+		address := resolver.Address{Addr: target.target, ServerName: serverName} //nolint:exhaustruct
+		allAddresses = append(allAddresses, address)
+
+		if target.state != grpcTargetStateEjected {
+			addresses = append(addresses, address)
+		}
+	}
+
+	if len(addresses) == 0 {
+		addresses = allAddresses
+	}
+
+	return resolver.State{Addresses: addresses} //nolint:exhaustruct
+}
+
+// Record the result of a health check.  Targets are ejected after grpcHealthCheckEjectAfter consecutive failures
+// and restored on success.
+// Returns the number of consecutive failures.
+func (balancer *grpcBalancer) setHealth(target *grpcBalancerTarget, err error) int {
+	balancer.mutex.Lock()
+
+	previousState := target.state
+
+	if err != nil {
+		target.failures++
+		target.lastError = err.Error()
+
+		if target.failures >= grpcHealthCheckEjectAfter {
+			target.state = grpcTargetStateEjected
+		}
+	} else {
+		target.failures = 0
+		target.state = grpcTargetStateServing
+	}
+
+	failures := target.failures
+	isChanged := (previousState == grpcTargetStateEjected) != (target.state == grpcTargetStateEjected)
+	state := balancer.resolverState()
+	resolvers := make([]*grpcBalancerResolver, 0, len(balancer.resolvers))
+
+	for grpcResolver := range balancer.resolvers {
+		resolvers = append(resolvers, grpcResolver)
+	}
+
+	balancer.mutex.Unlock()
+
+	if isChanged {
+		for _, grpcResolver := range resolvers {
+			_ = grpcResolver.clientConn.UpdateState(state)
+		}
+	}
+
+	return failures
+}
+
+// The health of each target, for the Console overview.
+func (balancer *grpcBalancer) statuses() []GrpcTargetStatus {
+	if balancer == nil {
+		return nil
+	}
+
+	balancer.mutex.Lock()
+	defer balancer.mutex.Unlock()
+
+	result := make([]GrpcTargetStatus, 0, len(balancer.targets))
+
+	for _, target := range balancer.targets {
+		targetStatus := GrpcTargetStatus{
+			Failures:  target.failures,
+			LastError: target.lastError,
+			State:     target.state,
+			Status:    "orange",
+			Target:    target.target,
+		}
+
+		switch target.state {
+		case grpcTargetStateServing:
+			targetStatus.Status = "green"
+		case grpcTargetStateEjected:
+			targetStatus.Status = "red"
+		}
+
+		result = append(result, targetStatus)
+	}
+
+	return result
+}
+
+// The target to dial, resolved by the balancer.
+func (balancer *grpcBalancer) target() string {
+	return grpcBalancerScheme + ":///senzing"
+}
+
+// Health check every target until the context is done.
+func (balancer *grpcBalancer) watch(ctx context.Context) {
+	for _, target := range balancer.targets {
+		go balancer.watchTarget(ctx, target)
+	}
+}
+
+func (balancer *grpcBalancer) watchTarget(ctx context.Context, target *grpcBalancerTarget) {
+	connection, err := grpc.NewClient(target.target, balancer.targetDialOptions...)
+	if err != nil {
+		balancer.setHealth(target, wraperror.Errorf(err, "grpc.NewClient %s", target.target))
+
+		return
+	}
+
+	defer connection.Close()
+
+	healthClient := grpc_health_v1.NewHealthClient(connection)
+
+	for {
+		err := checkGrpcHealth(ctx, healthClient)
+		if ctx.Err() != nil {
+			return
+		}
+
+		failures := balancer.setHealth(target, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(grpcHealthCheckDelay(failures)):
+		}
+	}
+}
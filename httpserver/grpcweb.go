@@ -0,0 +1,420 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Passes gRPC messages through as bytes, so that no generated code is needed to forward them.
+type grpcWebCodec struct{}
+
+// Decodes the base64 of a grpc-web-text request.  Clients may send it in separately padded chunks,
+// so it is decoded one 4-character quantum at a time.
+type grpcWebTextReader struct {
+	body    *bufio.Reader
+	decoded []byte // Not yet read.
+}
+
+// Writes the frames of a gRPC-Web response, sending the response headers before the first.
+type grpcWebResponseWriter struct {
+	isHeaderWritten bool
+	isText          bool
+	writer          http.ResponseWriter
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	grpcWebContentType      = "application/grpc-web"
+	grpcWebFrameHeaderBytes = 5
+	grpcWebTextContentType  = "application/grpc-web-text"
+	grpcWebTextQuantumBytes = 4
+	grpcWebTrailerFlag      = 0x80
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// Request headers a gRPC-Web client sends, allowed on cross-origin requests.
+var grpcWebCORSHeaders = []string{"Grpc-Timeout", "X-Grpc-Web", "X-User-Agent"}
+
+// Request headers that describe the HTTP request rather than the call, so are not forwarded as gRPC metadata.
+var grpcWebSkippedHeaders = map[string]bool{
+	"accept":          true,
+	"accept-encoding": true,
+	"accept-language": true,
+	"connection":      true,
+	"content-length":  true,
+	"content-type":    true,
+	"cookie":          true,
+	"host":            true,
+	"origin":          true,
+	"referer":         true,
+	"te":              true,
+	"user-agent":      true,
+	"x-grpc-web":      true,
+	"x-user-agent":    true,
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Read the single message of a unary or server-streaming gRPC-Web request.
+// gRPC-Web has no client streaming, so nothing after the first frame is read.
+func decodeGrpcWebRequest(body io.Reader, isText bool) ([]byte, error) {
+	if isText {
+		body = &grpcWebTextReader{body: bufio.NewReader(body), decoded: nil}
+	}
+
+	header := make([]byte, grpcWebFrameHeaderBytes)
+
+	_, err := io.ReadFull(body, header)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "gRPC-Web request has no message")
+	}
+
+	if header[0] != 0 {
+		return nil, wraperror.Errorf(errForPackage, "gRPC-Web request messages must not be compressed")
+	}
+
+	// Not allocated up front, so that the length claimed by the header costs nothing until it is sent.
+	length := binary.BigEndian.Uint32(header[1:])
+
+	message, err := io.ReadAll(io.LimitReader(body, int64(length)))
+	if err != nil {
+		return nil, wraperror.Errorf(err, "ReadAll")
+	}
+
+	if uint64(len(message)) < uint64(length) {
+		return nil, wraperror.Errorf(errForPackage, "gRPC-Web request message is truncated")
+	}
+
+	return message, nil
+}
+
+func encodeGrpcWebFrame(flag byte, payload []byte) []byte {
+	result := make([]byte, grpcWebFrameHeaderBytes, grpcWebFrameHeaderBytes+len(payload))
+	result[0] = flag
+	binary.BigEndian.PutUint32(result[1:], uint32(len(payload))) //nolint:gosec
+
+	return append(result, payload...)
+}
+
+// Percent-encode a status message as the gRPC protocol requires.
+func encodeGrpcMessage(message string) string {
+	var result strings.Builder
+
+	for index := range len(message) {
+		character := message[index]
+		if character < ' ' || character > '~' || character == '%' {
+			fmt.Fprintf(&result, "%%%02X", character)
+		} else {
+			result.WriteByte(character)
+		}
+	}
+
+	return result.String()
+}
+
+// The gRPC metadata of a gRPC-Web request.  Binary ("-bin") values arrive base64 encoded.
+func grpcWebMetadata(header http.Header) metadata.MD {
+	result := metadata.MD{}
+
+	for name, values := range header {
+		key := strings.ToLower(name)
+		if grpcWebSkippedHeaders[key] || strings.HasPrefix(key, "grpc-") || strings.HasPrefix(key, "sec-") ||
+			strings.HasPrefix(key, "access-control-") {
+			continue
+		}
+
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				decoded, err := base64.StdEncoding.DecodeString(value)
+				if err != nil {
+					decoded, err = base64.RawStdEncoding.DecodeString(value)
+				}
+
+				if err != nil {
+					continue
+				}
+
+				value = string(decoded)
+			}
+
+			result.Append(key, value)
+		}
+	}
+
+	return result
+}
+
+// Parse a Grpc-Timeout header, like "30S" or "500m".
+func grpcWebTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 { //nolint:mnd
+		return 0, false
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+
+	unit, found := units[value[len(value)-1]]
+
+	return time.Duration(amount) * unit, found
+}
+
+// The payload of a trailer frame: the call's status followed by its trailing metadata.
+func grpcWebTrailer(callStatus *status.Status, trailer metadata.MD) []byte {
+	var result bytes.Buffer
+
+	fmt.Fprintf(&result, "grpc-status: %d\r\n", callStatus.Code())
+
+	if len(callStatus.Message()) > 0 {
+		fmt.Fprintf(&result, "grpc-message: %s\r\n", encodeGrpcMessage(callStatus.Message()))
+	}
+
+	for key, values := range trailer {
+		for _, value := range values {
+			fmt.Fprintf(&result, "%s: %s\r\n", key, grpcWebMetadataValue(key, value))
+		}
+	}
+
+	return result.Bytes()
+}
+
+func grpcWebMetadataValue(key string, value string) string {
+	if strings.HasSuffix(key, "-bin") {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	return value
+}
+
+// Forward a gRPC-Web request to a gRPC server as a server-streaming call, which also covers unary calls.
+func serveGrpcWeb(connection grpc.ClientConnInterface, writer http.ResponseWriter, request *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	isText := mediaType == grpcWebTextContentType || mediaType == grpcWebTextContentType+"+proto"
+	isBinary := mediaType == grpcWebContentType || mediaType == grpcWebContentType+"+proto"
+
+	switch {
+	case request.Method != http.MethodPost:
+		writer.Header().Set("Allow", http.MethodPost)
+		writeProblem(writer, request, http.StatusMethodNotAllowed, "gRPC-Web requests must use POST")
+
+		return
+	case !isText && !isBinary:
+		writeProblem(writer, request, http.StatusUnsupportedMediaType, "Content-Type must be application/grpc-web")
+
+		return
+	case strings.Count(request.URL.Path, "/") != 2: //nolint:mnd
+		writeProblem(writer, request, http.StatusNotFound, "path must be /<package.Service>/<Method>")
+
+		return
+	}
+
+	message, err := decodeGrpcWebRequest(request.Body, isText)
+	if err != nil {
+		writeProblem(writer, request, http.StatusBadRequest, "malformed gRPC-Web request")
+
+		return
+	}
+
+	ctx := metadata.NewOutgoingContext(request.Context(), grpcWebMetadata(request.Header))
+
+	if timeout, found := grpcWebTimeout(request.Header.Get("Grpc-Timeout")); found {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	responseWriter := &grpcWebResponseWriter{
+		isHeaderWritten: false,
+		isText:          isText,
+		writer:          writer,
+	}
+	writer.Header().Set("Content-Type", mediaType)
+
+	stream, err := connection.NewStream(
+		ctx,
+		&grpc.StreamDesc{StreamName: "", Handler: nil, ServerStreams: true, ClientStreams: false},
+		request.URL.Path,
+		grpc.ForceCodec(grpcWebCodec{}),
+	)
+	if err == nil {
+		err = stream.SendMsg(&message)
+	}
+
+	if err == nil {
+		err = stream.CloseSend()
+	}
+
+	for err == nil {
+		var response []byte
+
+		err = stream.RecvMsg(&response)
+		if err == nil {
+			responseWriter.writeHeader(stream)
+			responseWriter.writeFrame(0, response)
+		}
+	}
+
+	trailer := metadata.MD{}
+	if stream != nil {
+		trailer = stream.Trailer()
+	}
+
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	responseWriter.writeHeader(stream)
+	responseWriter.writeFrame(grpcWebTrailerFlag, grpcWebTrailer(status.Convert(err), trailer))
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Dial the Senzing gRPC server and forward gRPC-Web requests to it.
+func (httpServer *BasicHTTPServer) getGrpcWebHandler(ctx context.Context) (http.Handler, error) {
+	_ = ctx
+	grpcTarget, grpcDialOptions := httpServer.getGrpcClientSettings()
+
+	connection, err := grpc.NewClient(grpcTarget, grpcDialOptions...)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "grpc.NewClient %s", grpcTarget)
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		serveGrpcWeb(connection, writer, request)
+	}), nil
+}
+
+func (grpcWebCodec) Marshal(value any) ([]byte, error) {
+	message, isBytes := value.(*[]byte)
+	if !isBytes {
+		return nil, wraperror.Errorf(errForPackage, "gRPC-Web messages must be *[]byte, not %T", value)
+	}
+
+	return *message, nil
+}
+
+func (grpcWebCodec) Name() string {
+	return "proto"
+}
+
+func (grpcWebCodec) Unmarshal(data []byte, value any) error {
+	message, isBytes := value.(*[]byte)
+	if !isBytes {
+		return wraperror.Errorf(errForPackage, "gRPC-Web messages must be *[]byte, not %T", value)
+	}
+
+	*message = bytes.Clone(data)
+
+	return nil
+}
+
+func (reader *grpcWebTextReader) Read(buffer []byte) (int, error) {
+	for len(reader.decoded) == 0 {
+		quantum := make([]byte, 0, grpcWebTextQuantumBytes)
+
+		for len(quantum) < grpcWebTextQuantumBytes {
+			character, err := reader.body.ReadByte()
+			if errors.Is(err, io.EOF) && len(quantum) > 0 {
+				return 0, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return 0, err //nolint:wrapcheck // io.EOF must reach the caller unwrapped.
+			}
+
+			if !unicode.IsSpace(rune(character)) {
+				quantum = append(quantum, character)
+			}
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(string(quantum))
+		if err != nil {
+			return 0, wraperror.Errorf(err, "DecodeString")
+		}
+
+		reader.decoded = decoded
+	}
+
+	count := copy(buffer, reader.decoded)
+	reader.decoded = reader.decoded[count:]
+
+	return count, nil
+}
+
+// Send the response headers, including the header metadata of the stream, if any.
+func (responseWriter *grpcWebResponseWriter) writeHeader(stream grpc.ClientStream) {
+	if responseWriter.isHeaderWritten {
+		return
+	}
+
+	responseWriter.isHeaderWritten = true
+
+	if stream != nil {
+		headerMetadata, err := stream.Header()
+		if err == nil {
+			header := responseWriter.writer.Header()
+
+			for key, values := range headerMetadata {
+				if key == "content-type" {
+					continue
+				}
+
+				for _, value := range values {
+					header.Add(key, grpcWebMetadataValue(key, value))
+				}
+			}
+		}
+	}
+
+	responseWriter.writer.WriteHeader(http.StatusOK)
+}
+
+func (responseWriter *grpcWebResponseWriter) writeFrame(flag byte, payload []byte) {
+	frame := encodeGrpcWebFrame(flag, payload)
+	if responseWriter.isText {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+
+	_, _ = responseWriter.writer.Write(frame)
+	_ = http.NewResponseController(responseWriter.writer).Flush()
+}
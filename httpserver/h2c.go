@@ -0,0 +1,35 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+/*
+Wrap a handler so that, if EnableH2C is set, HTTP/2 is served without TLS, as service meshes that terminate TLS
+speak it.  Clients either start with the HTTP/2 preface ("prior knowledge") or upgrade an HTTP/1.1 request
+with "Upgrade: h2c".  The http.Server of Serve accepts prior-knowledge connections itself, so this handles
+upgrades there, and both modes where the Handler is embedded in another http.Server.
+*/
+func (httpServer *BasicHTTPServer) h2cHandler(next http.Handler) http.Handler {
+	if !httpServer.EnableH2C {
+		return next
+	}
+
+	return h2c.NewHandler(next, new(http2.Server))
+}
+
+// The protocols Serve accepts.  HTTP/2 without TLS only if EnableH2C is set.
+func (httpServer *BasicHTTPServer) serverProtocols() *http.Protocols {
+	result := &http.Protocols{}
+	result.SetHTTP1(true)
+	result.SetUnencryptedHTTP2(httpServer.EnableH2C)
+
+	return result
+}
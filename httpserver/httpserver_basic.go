@@ -1,14 +1,15 @@
 package httpserver
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"embed"
 	"fmt"
 	"html/template"
 	"io/fs"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"time"
 
@@ -28,46 +29,109 @@ import (
 
 // BasicHTTPServer is the default implementation of the HttpServer interface.
 type BasicHTTPServer struct {
-	APIUrlRoutePrefix         string // IMPROVE: Only works with "api"
+	AdminToken                string         // Bearer token of the admin API.  If empty, the admin API is not served.
+	APIUrlRoutePrefix         string         // IMPROVE: Only works with "api"
+	AllowedCIDRs              []netip.Prefix // If not empty, only these clients are served.
+	APIInstances              []APIInstance  // Additional Senzing REST APIs, mounted under APIUrlRoutePrefix.
 	AvoidServing              bool
+	CompressionContentTypes   []string // Media types to compress.  If empty, common text types.
+	CompressionMinSize        int      // Bytes.  Smaller responses are not compressed.  If 0, 1024.
+	CORSAllowCredentials      bool
+	CORSAllowedHeaders        []string
+	CORSAllowedMethods        []string
+	CORSAllowedOrigins        []string // Exact origins, "*", or wildcards like "https://*.example.com".
+	CORSMaxAge                int      // Seconds a preflight response may be cached.
+	DeniedCIDRs               []netip.Prefix
 	EnableAll                 bool
+	EnableCompression         bool
+	EnableGrpcWeb             bool // Requires a GrpcTarget.
+	EnableH2C                 bool // Serve HTTP/2 without TLS, by prior knowledge or by upgrade.
 	EnableSenzingRestAPI      bool
 	EnableSwaggerUI           bool
 	EnableXterm               bool
 	GrpcDialOptions           []grpc.DialOption
+	GrpcLoadBalancing         string // "round_robin" or "least_request".  If empty, round robin.
 	GrpcTarget                string
+	GrpcTargets               []string // "host:port" of Senzing gRPC servers to balance across.  Replaces GrpcTarget.
+	GrpcWebURLRoutePrefix     string
+	HSTSMaxAge                int // Seconds.  Only sent on requests made over TLS.
 	LogLevelName              string
+	LogLevelRevertAfter       time.Duration    // How long log level changes last.  If 0, 15 minutes.
+	Maintenance               bool             // Start in maintenance mode: Senzing REST API writes are refused.
+	MaintenanceDisablesXterm  bool             // In maintenance mode, XTerm is refused too.
+	MaintenanceMessage        string           // Shown on Console pages and in 503 responses.
+	MaintenanceRetryAfter     int              // Seconds sent in Retry-After.  If 0, 300.
+	MaxHeaderBytes            int              // If 0, http.DefaultMaxHeaderBytes.
+	MaxRequestBodyBytes       map[string]int64 // Keyed like RateLimits.  Overrides the defaults.  0 is unlimited.
+	Middlewares               []Middleware     // Wrap every request.  The first is outermost.
+	MirrorPercent             int              // Of read-only Senzing REST API requests copied to MirrorURL.
+	MirrorReportFile          string           // JSON lines comparing MirrorURL's responses with the primary's.
+	MirrorURL                 string           // Base URL of a shadow server, e.g. "http://shadow.example.com:8261".
 	ObserverOrigin            string
 	Observers                 []observer.Observer
 	OpenAPISpecificationRest  []byte
+	RateLimitKeyHeader        string               // Also limits each value of this header, not only each client IP.
+	RateLimits                map[string]RateLimit // Keyed by service name, optionally with "/" and route class.
 	ReadHeaderTimeout         time.Duration
+	ReverseProxies            []ReverseProxy             // Services forwarding to upstream HTTP servers.
+	SecurityHeaders           map[string]SecurityHeaders // Keyed by service name.  Overrides the defaults.
 	SenzingSettings           string
 	SenzingInstanceName       string
 	SenzingVerboseLogging     int64
 	ServerAddress             string
 	ServerOptions             []senzingrestapi.ServerOption
 	ServerPort                int
-	SwaggerURLRoutePrefix     string // IMPROVE: Only works with "swagger"
+	ServiceAllowedCIDRs       map[string][]netip.Prefix // Keyed by service name.  Replaces AllowedCIDRs.
+	ServiceDeniedCIDRs        map[string][]netip.Prefix // Keyed by service name.  Added to DeniedCIDRs.
+	ServiceMiddlewares        map[string][]Middleware   // Keyed by service name.  Run after access control and CORS.
+	StaticCacheMaxAge         int                       // Seconds browsers may reuse static assets unrevalidated.
+	StaticDirectory           string                    // Served at "/", layered over the embedded assets.
+	StaticDirectoryExclusive  bool                      // Serve StaticDirectory instead of the embedded assets.
+	StaticSPAFallback         bool                      // Serve StaticDirectory's index.html for unknown routes.
+	SwaggerURLRoutePrefix     string                    // IMPROVE: Only works with "swagger"
+	TemplatesDirectory        string                    // Extra Console pages, rendered under /site/.
+	TrustedProxies            []netip.Prefix            // Clients whose X-Forwarded-Proto is believed, for HSTS.
 	TtyOnly                   bool
+	Version                   string // Reported to Console pages by the "version" template function.
 	XtermAllowedHostnames     []string
 	XtermArguments            []string
 	XtermCommand              string
 	XtermConnectionErrorLimit int
 	XtermKeepalivePingTimeout int
 	XtermMaxBufferSizeBytes   int
-	XtermURLRoutePrefix       string // IMPROVE: Only works with "xterm"
+	XtermURLRoutePrefix       string        // IMPROVE: Only works with "xterm"
+	grpcBalancer              *grpcBalancer // Built from GrpcTargets by Serve or Handler.
+	logLevel                  *slog.LevelVar
+	logLevelSwitch            *logLevelSwitch // Changed through the admin API and by SIGUSR1 and SIGUSR2.
+	logger                    *slog.Logger
+	maintenance               *maintenance // Built by Serve or Handler.  Changed through the admin API.
+	mirror                    *mirror
+	rateLimiter               *rateLimiter
+	serviceHealth             *serviceHealth                // Of the ServiceHealthCheckers, checked on a timer.
+	serviceSwitches           *serviceSwitches              // Changed through the admin API.
+	services                  []Service                     // Added by RegisterService.
+	siteTemplates             map[string]*template.Template // Keyed by request path.
 }
 
+// TemplateVariables are the data of Console pages.  The BasicHTTPServer is a copy without secrets.
 type TemplateVariables struct {
 	BasicHTTPServer
-	APIServerStatus string
-	APIServerURL    string
-	HTMLTitle       string
-	RequestHost     string
-	SwaggerStatus   string
-	SwaggerURL      string
-	XtermStatus     string
-	XtermURL        string
+	APIServerStatus    string
+	APIServerURL       string
+	CSPNonce           string
+	ErrorDetail        string
+	ErrorStatus        int
+	ErrorTitle         string
+	GrpcTargetStatuses []GrpcTargetStatus
+	HTMLTitle          string
+	MaintenanceBanner  string // Empty unless in maintenance mode.
+	RequestHost        string
+	RequestID          string
+	Services           []ConsoleService
+	SwaggerStatus      string
+	SwaggerURL         string
+	XtermStatus        string
+	XtermURL           string
 }
 
 // ----------------------------------------------------------------------------
@@ -93,19 +157,10 @@ Output
 */
 
 func (httpServer *BasicHTTPServer) Serve(ctx context.Context) error {
-	var err error
-
-	var userMessages []string
-
-	rootMux := http.NewServeMux()
-
-	// Add to root Mux.
-
-	userMessages = append(userMessages, httpServer.addAPIToMux(ctx, rootMux)...)
-	userMessages = append(userMessages, httpServer.addSwaggerToMux(ctx, rootMux)...)
-	userMessages = append(userMessages, httpServer.addXtermToMux(ctx, rootMux)...)
-	userMessages = append(userMessages, httpServer.addSiteToMux(ctx, rootMux)...)
-	userMessages = append(userMessages, httpServer.addStaticToMux(ctx, rootMux)...)
+	rootHandler, userMessages, err := httpServer.getRootHandler(ctx)
+	if err != nil {
+		return wraperror.Errorf(err, "getRootHandler")
+	}
 
 	// Start service.
 
@@ -119,9 +174,13 @@ func (httpServer *BasicHTTPServer) Serve(ctx context.Context) error {
 	server := http.Server{
 		ReadHeaderTimeout: httpServer.ReadHeaderTimeout,
 		Addr:              listenOnAddress,
-		Handler:           rootMux,
+		Handler:           rootHandler,
+		MaxHeaderBytes:    httpServer.MaxHeaderBytes,
+		Protocols:         httpServer.serverProtocols(),
 	}
 
+	httpServer.watchLogLevelSignals(ctx)
+
 	// Start a web browser.  Unless disabled.
 
 	if !httpServer.TtyOnly {
@@ -136,108 +195,174 @@ func (httpServer *BasicHTTPServer) Serve(ctx context.Context) error {
 }
 
 // ----------------------------------------------------------------------------
-// Private methods
+// Public methods
 // ----------------------------------------------------------------------------
 
-func (httpServer *BasicHTTPServer) addAPIToMux(
-	ctx context.Context,
-	rootMux *http.ServeMux,
-) []string {
-	var result []string
+/*
+The Handler method returns the handler that Serve listens with.
+It allows the services to be embedded in another http.Server or tested with httptest.
 
-	if httpServer.EnableAll || httpServer.EnableSenzingRestAPI {
-		senzingAPIMux := httpServer.getSenzingAPIMux(ctx)
-		rootMux.Handle(fmt.Sprintf("/%s/", httpServer.APIUrlRoutePrefix), http.StripPrefix("/api", senzingAPIMux))
-		result = append(result, fmt.Sprintf(
-			"Serving Senzing REST API at http://localhost:%d/%s",
-			httpServer.ServerPort,
-			httpServer.APIUrlRoutePrefix,
-		))
-	}
+Input
+  - ctx: A context to control lifecycle.
 
-	return result
+Output
+  - An http.Handler serving all enabled services.
+  - An error if a service could not be built.
+*/
+func (httpServer *BasicHTTPServer) Handler(ctx context.Context) (http.Handler, error) {
+	rootHandler, _, err := httpServer.getRootHandler(ctx)
+
+	return rootHandler, wraperror.Errorf(err, "getRootHandler")
 }
 
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
 func (httpServer *BasicHTTPServer) addSiteToMux(
 	ctx context.Context,
 	rootMux *http.ServeMux,
-) []string {
+) ([]string, error) {
 	var result []string
 
 	_ = ctx
 
-	rootMux.HandleFunc("/site/", httpServer.siteFunc)
+	siteTemplates, err := httpServer.parseSiteTemplates()
+	if err != nil {
+		return nil, wraperror.Errorf(err, "parseSiteTemplates")
+	}
+
+	httpServer.siteTemplates = siteTemplates
+	rootMux.Handle(
+		"/"+routePrefixSite+"/",
+		httpServer.serviceHandler(ServiceNameConsole, http.HandlerFunc(httpServer.siteFunc)),
+	)
 	result = append(result, fmt.Sprintf("Serving Console at          http://localhost:%d\n", httpServer.ServerPort))
 
-	return result
+	return result, nil
 }
 
 func (httpServer *BasicHTTPServer) addStaticToMux(
 	ctx context.Context,
 	rootMux *http.ServeMux,
-) []string {
+) ([]string, error) {
 	result := []string{}
 
 	_ = ctx
 
 	rootDir, err := fs.Sub(static, "static/root")
 	if err != nil {
-		panic(err)
+		return nil, wraperror.Errorf(err, "fs.Sub")
 	}
 
-	rootMux.Handle("/", http.StripPrefix("/", http.FileServer(http.FS(rootDir))))
+	rootCache, err := newStaticAssetCache(httpServer.StaticCacheMaxAge, rootDir)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "newStaticAssetCache")
+	}
 
-	return result
-}
+	var rootHandler http.Handler = rootCache.handler(http.StripPrefix("/", http.FileServer(http.FS(rootDir))))
 
-func (httpServer *BasicHTTPServer) addSwaggerToMux(
-	ctx context.Context,
-	rootMux *http.ServeMux,
-) []string {
-	var result []string
+	if len(httpServer.StaticDirectory) > 0 {
+		// Unlike os.DirFS, a Root does not follow symbolic links out of the directory.
+		staticRoot, err := os.OpenRoot(httpServer.StaticDirectory)
+		if err != nil {
+			return nil, wraperror.Errorf(err, "OpenRoot: %s", httpServer.StaticDirectory)
+		}
 
-	if httpServer.EnableAll || httpServer.EnableSwaggerUI {
-		swaggerUIMux := httpServer.getSwaggerUIMux(ctx)
-		rootMux.Handle(
-			fmt.Sprintf("/%s/", httpServer.SwaggerURLRoutePrefix),
-			http.StripPrefix("/swagger", swaggerUIMux),
-		)
+		site := &staticSite{
+			cacheControl:   formatCacheControl(httpServer.StaticCacheMaxAge),
+			directory:      staticRoot.FS(),
+			directoryFiles: nil,
+			embedded:       rootDir,
+			embeddedFiles:  rootHandler,
+			isSPA:          httpServer.StaticSPAFallback,
+		}
+		site.directoryFiles = http.FileServerFS(site.directory)
 
+		if httpServer.StaticDirectoryExclusive {
+			site.embedded = nil
+		}
+
+		rootHandler = site
 		result = append(result, fmt.Sprintf(
-			"Serving SwaggerUI at        http://localhost:%d/%s\n",
+			"Serving static files at     http://localhost:%d from %s\n",
 			httpServer.ServerPort,
-			httpServer.SwaggerURLRoutePrefix,
+			httpServer.StaticDirectory,
 		))
 	}
 
-	return result
+	rootMux.Handle("/", httpServer.serviceHandler(ServiceNameConsole, rootHandler))
+
+	return result, nil
 }
 
-func (httpServer *BasicHTTPServer) addXtermToMux(
-	ctx context.Context,
-	rootMux *http.ServeMux,
-) []string {
-	var result []string
+// Build the root handler of all enabled services and the messages describing them.
+// Every request gets a request ID, and a panic while serving it is recovered and reported.
+func (httpServer *BasicHTTPServer) getRootHandler(ctx context.Context) (http.Handler, []string, error) {
+	var userMessages []string
+
+	rootMux := http.NewServeMux()
+	httpServer.initializeLogger()
+	httpServer.rateLimiter = newRateLimiter(httpServer.RateLimits, httpServer.RateLimitKeyHeader)
+	httpServer.grpcBalancer = nil
+
+	if len(httpServer.GrpcTargets) > 0 {
+		httpServer.grpcBalancer = newGrpcBalancer(
+			httpServer.GrpcTargets,
+			httpServer.GrpcLoadBalancing,
+			httpServer.GrpcDialOptions,
+		)
+		httpServer.grpcBalancer.watch(ctx)
+	}
 
-	if httpServer.EnableAll || httpServer.EnableXterm {
-		err := os.Setenv("SENZING_ENGINE_CONFIGURATION_JSON", httpServer.SenzingSettings)
+	httpServer.maintenance = newMaintenance(httpServer)
+	httpServer.serviceHealth = newServiceHealth()
+	httpServer.serviceSwitches = newServiceSwitches()
+	httpServer.mirror = nil
+
+	if len(httpServer.MirrorURL) > 0 {
+		var err error
+
+		httpServer.mirror, err = newMirror(ctx, httpServer)
 		if err != nil {
-			panic(err)
+			return nil, nil, wraperror.Errorf(err, "newMirror")
 		}
+	}
 
-		xtermMux := httpServer.getXtermMux(ctx)
-		rootMux.Handle(fmt.Sprintf("/%s/", httpServer.XtermURLRoutePrefix), http.StripPrefix("/xterm", xtermMux))
-		result = append(result, fmt.Sprintf(
-			"Serving XTerm at            http://localhost:%d/%s",
-			httpServer.ServerPort,
-			httpServer.XtermURLRoutePrefix,
-		))
+	// Add to root Mux.
+
+	for _, addToMux := range []func(context.Context, *http.ServeMux) ([]string, error){
+		httpServer.addServicesToMux,
+		httpServer.addSiteToMux,
+		httpServer.addStaticToMux,
+	} {
+		messages, err := addToMux(ctx, rootMux)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		userMessages = append(userMessages, messages...)
 	}
 
-	return result
+	rootHandler := chainMiddlewares(rootMux, httpServer.Middlewares)
+	rootHandler = httpServer.recoveryHandler(rootHandler)
+	rootHandler = httpServer.errorPagesHandler(rootHandler)
+	rootHandler = requestIDHandler(rootHandler)
+	rootHandler = httpServer.h2cHandler(rootHandler)
+
+	return rootHandler, userMessages, nil
+}
+
+// The target and dial options of the Senzing gRPC server, or of the balancer across GrpcTargets.
+func (httpServer *BasicHTTPServer) getGrpcClientSettings() (string, []grpc.DialOption) {
+	if httpServer.grpcBalancer != nil {
+		return httpServer.grpcBalancer.target(), httpServer.grpcBalancer.dialOptions()
+	}
+
+	return httpServer.GrpcTarget, httpServer.GrpcDialOptions
 }
 
-func (httpServer *BasicHTTPServer) getServerStatus(active bool) string {
+func (httpServer *BasicHTTPServer) getServerStatus(serviceName string, active bool) string {
 	result := "red"
 	if httpServer.EnableAll {
 		result = "green"
@@ -247,10 +372,14 @@ func (httpServer *BasicHTTPServer) getServerStatus(active bool) string {
 		result = "green"
 	}
 
+	if httpServer.serviceSwitches.isDisabled(serviceName) {
+		result = "red"
+	}
+
 	return result
 }
 
-func (httpServer *BasicHTTPServer) getServerURL(active bool, url string) string {
+func (httpServer *BasicHTTPServer) getServerURL(serviceName string, active bool, url string) string {
 	result := ""
 	if httpServer.EnableAll {
 		result = url
@@ -260,103 +389,154 @@ func (httpServer *BasicHTTPServer) getServerURL(active bool, url string) string
 		result = url
 	}
 
+	if httpServer.serviceSwitches.isDisabled(serviceName) {
+		result = ""
+	}
+
+	return result
+}
+
+// A copy of the server for Console pages, without the secrets that no page, including those of TemplatesDirectory,
+// may show: the admin token, the Senzing settings with their database credentials, and the URLs and request headers
+// of other servers, which may carry credentials too.
+func (httpServer *BasicHTTPServer) getTemplateServer() BasicHTTPServer {
+	result := *httpServer
+	result.AdminToken = ""
+	result.APIInstances = nil
+	result.MirrorURL = ""
+	result.ReverseProxies = nil
+	result.SenzingSettings = ""
+
 	return result
 }
 
-func (httpServer *BasicHTTPServer) openAPIFunc(ctx context.Context, openAPISpecification []byte) http.HandlerFunc {
+func (httpServer *BasicHTTPServer) hasGrpcTarget() bool {
+	return len(httpServer.GrpcTarget) > 0 || len(httpServer.GrpcTargets) > 0
+}
+
+func (httpServer *BasicHTTPServer) openAPIFunc(
+	ctx context.Context,
+	openAPISpecification []byte,
+) (http.HandlerFunc, error) {
 	_ = ctx
-	_ = openAPISpecification
+
+	openAPISpecificationTemplate, err := parseTemplate("OpenApiTemplate", string(openAPISpecification), nil)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "parseTemplate")
+	}
 
 	return func(writer http.ResponseWriter, request *http.Request) {
 		var bytesBuffer bytes.Buffer
 
-		bufioWriter := bufio.NewWriter(&bytesBuffer)
-
-		openAPISpecificationTemplate, err := template.New("OpenApiTemplate").
-			Parse(string(httpServer.OpenAPISpecificationRest))
-		if err != nil {
-			panic(err)
-		}
-
 		templateVariables := TemplateVariables{
 			RequestHost: request.Host,
 		}
 
-		err = openAPISpecificationTemplate.Execute(bufioWriter, templateVariables)
+		err := openAPISpecificationTemplate.Execute(&bytesBuffer, templateVariables)
 		if err != nil {
-			panic(err)
-		}
+			httpServer.writeInternalServerError(writer, request, err)
 
-		_, err = writer.Write(bytesBuffer.Bytes())
-		if err != nil {
-			panic(err)
+			return
 		}
-	}
+
+		_, _ = writer.Write(bytesBuffer.Bytes())
+	}, nil
 }
 
 func (httpServer *BasicHTTPServer) populateStaticTemplate(
 	responseWriter http.ResponseWriter,
 	request *http.Request,
-	filepath string,
+	requestPath string,
 	templateVariables TemplateVariables,
 ) {
 	_ = request
 
-	templateBytes, err := static.ReadFile(filepath)
-	if err != nil {
-		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	templateParsed, found := httpServer.siteTemplates[requestPath]
+	if !found {
+		http.NotFound(responseWriter, request)
 
 		return
 	}
 
-	templateParsed, err := template.New("HtmlTemplate").Parse(string(templateBytes))
-	if err != nil {
-		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-
-		return
-	}
+	// Render fully before writing, so that an error does not leave a partial page.
+	var bytesBuffer bytes.Buffer
 
-	err = templateParsed.Execute(responseWriter, templateVariables)
+	err := templateParsed.Execute(&bytesBuffer, templateVariables)
 	if err != nil {
-		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		httpServer.writeInternalServerError(responseWriter, request, err)
 
 		return
 	}
+
+	responseWriter.Header().Set("Content-Type", "text/html")
+	_, _ = responseWriter.Write(bytesBuffer.Bytes())
 }
 
 // --- http.ServeMux ----------------------------------------------------------
 
-func (httpServer *BasicHTTPServer) getSenzingAPIMux(ctx context.Context) *senzingrestapi.Server {
+func (httpServer *BasicHTTPServer) getSenzingAPIMux(ctx context.Context) (*senzingrestapi.Server, error) {
+	grpcTarget, grpcDialOptions := httpServer.getGrpcClientSettings()
+	instance := APIInstance{
+		GrpcDialOptions:       grpcDialOptions,
+		GrpcTarget:            grpcTarget,
+		Name:                  "",
+		SenzingInstanceName:   httpServer.SenzingInstanceName,
+		SenzingSettings:       httpServer.SenzingSettings,
+		SenzingVerboseLogging: httpServer.SenzingVerboseLogging,
+	}
+
+	return httpServer.newSenzingAPIServer(ctx, instance, httpServer.APIUrlRoutePrefix)
+}
+
+// The Senzing REST API of the main API or an APIInstance.  SenzingSettings are used only without a GrpcTarget.
+func (httpServer *BasicHTTPServer) newSenzingAPIServer(
+	ctx context.Context,
+	instance APIInstance,
+	routePrefix string,
+) (*senzingrestapi.Server, error) {
 	_ = ctx
 	service := &senzingrestservice.BasicSenzingRestService{
-		GrpcDialOptions:          httpServer.GrpcDialOptions,
-		GrpcTarget:               httpServer.GrpcTarget,
+		GrpcDialOptions:          instance.GrpcDialOptions,
+		GrpcTarget:               instance.GrpcTarget,
 		LogLevelName:             httpServer.LogLevelName,
 		ObserverOrigin:           httpServer.ObserverOrigin,
 		Observers:                httpServer.Observers,
-		Settings:                 httpServer.SenzingSettings,
-		SenzingInstanceName:      httpServer.SenzingInstanceName,
-		SenzingVerboseLogging:    httpServer.SenzingVerboseLogging,
-		URLRoutePrefix:           httpServer.APIUrlRoutePrefix,
+		Settings:                 instance.SenzingSettings,
+		SenzingInstanceName:      instance.SenzingInstanceName,
+		SenzingVerboseLogging:    instance.SenzingVerboseLogging,
+		URLRoutePrefix:           routePrefix,
 		OpenAPISpecificationSpec: httpServer.OpenAPISpecificationRest,
 	}
 
 	srv, err := senzingrestapi.NewServer(service, httpServer.ServerOptions...)
 	if err != nil {
-		panic(err)
+		return nil, wraperror.Errorf(err, "senzingrestapi.NewServer")
 	}
 
-	return srv
+	httpServer.logLevelSwitch.add(service)
+
+	return srv, nil
 }
 
-func (httpServer *BasicHTTPServer) getSwaggerUIMux(ctx context.Context) *http.ServeMux {
+func (httpServer *BasicHTTPServer) getSwaggerUIMux(ctx context.Context) (*http.ServeMux, error) {
 	swaggerMux := swaggerui.Handler([]byte{}) // OpenAPI specification handled by openApiFunc()
-	swaggerFunc := swaggerMux.ServeHTTP
+
+	// The Swagger UI files are embedded in its package, so their ETags are learned as they are served.
+	swaggerCache, err := newStaticAssetCache(httpServer.StaticCacheMaxAge, nil)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "newStaticAssetCache")
+	}
+
+	openAPIFunc, err := httpServer.openAPIFunc(ctx, httpServer.OpenAPISpecificationRest)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "openAPIFunc")
+	}
+
 	submux := http.NewServeMux()
-	submux.HandleFunc("/", swaggerFunc)
-	submux.HandleFunc("/swagger_spec", httpServer.openAPIFunc(ctx, httpServer.OpenAPISpecificationRest))
+	submux.Handle("/", swaggerCache.handler(swaggerMux))
+	submux.HandleFunc("/swagger_spec", openAPIFunc)
 
-	return submux
+	return submux, nil
 }
 
 func (httpServer *BasicHTTPServer) getXtermMux(ctx context.Context) *http.ServeMux {
@@ -375,28 +555,71 @@ func (httpServer *BasicHTTPServer) getXtermMux(ctx context.Context) *http.ServeM
 
 // --- Http Funcs -------------------------------------------------------------
 
+// Wrap a service's handler with the middleware common to all services.
+// The outermost middleware runs first: access control, security headers, compression, CORS (API only),
+// the service's ServiceMiddlewares, the admin API's service switches, maintenance mode, rate limiting,
+// mirroring (API only), then request body limits.  Error pages replace only the Console's own errors.
+func (httpServer *BasicHTTPServer) serviceHandler(serviceName string, handler http.Handler) http.Handler {
+	if serviceName != ServiceNameConsole {
+		handler = errorPagesPassThroughHandler(handler)
+	}
+
+	handler = httpServer.bodyLimitHandler(serviceName, handler)
+
+	if serviceName == ServiceNameAPI {
+		handler = httpServer.mirror.handler(handler)
+	}
+
+	handler = httpServer.rateLimiter.handler(serviceName, handler)
+	handler = httpServer.maintenance.handler(serviceName, handler)
+	handler = httpServer.serviceSwitches.handler(serviceName, handler)
+	_, middlewares, _ := lookupRoute(httpServer.ServiceMiddlewares, serviceName)
+	handler = chainMiddlewares(handler, middlewares)
+
+	switch {
+	case isAPIServiceName(serviceName):
+		handler = newCORSPolicy(httpServer).handler(handler)
+	case serviceName == ServiceNameGrpcWeb:
+		handler = newCORSPolicy(httpServer, grpcWebCORSHeaders...).handler(handler)
+	}
+
+	handler = httpServer.compressionHandler(handler)
+	handler = httpServer.securityHeadersHandler(serviceName, handler)
+	handler = httpServer.accessControlHandler(serviceName, handler)
+
+	return handler
+}
+
 func (httpServer *BasicHTTPServer) siteFunc(writer http.ResponseWriter, request *http.Request) {
 	templateVariables := TemplateVariables{
-		BasicHTTPServer: *httpServer,
-		HTMLTitle:       "Senzing Tools",
+		BasicHTTPServer:   httpServer.getTemplateServer(),
+		HTMLTitle:         "Senzing Tools",
+		MaintenanceBanner: httpServer.maintenance.banner(),
 		APIServerURL: httpServer.getServerURL(
+			ServiceNameAPI,
 			httpServer.EnableSenzingRestAPI,
 			fmt.Sprintf("http://%s/api", request.Host),
 		),
-		APIServerStatus: httpServer.getServerStatus(httpServer.EnableSenzingRestAPI),
+		APIServerStatus:    httpServer.getServerStatus(ServiceNameAPI, httpServer.EnableSenzingRestAPI),
+		CSPNonce:           securityHeadersNonce(request.Context()),
+		GrpcTargetStatuses: httpServer.grpcBalancer.statuses(),
+		RequestHost:        request.Host,
 		SwaggerURL: httpServer.getServerURL(
+			ServiceNameSwagger,
 			httpServer.EnableSwaggerUI,
 			fmt.Sprintf("http://%s/swagger", request.Host),
 		),
-		SwaggerStatus: httpServer.getServerStatus(httpServer.EnableSwaggerUI),
-		XtermURL:      httpServer.getServerURL(httpServer.EnableXterm, fmt.Sprintf("http://%s/xterm", request.Host)),
-		XtermStatus:   httpServer.getServerStatus(httpServer.EnableXterm),
+		SwaggerStatus: httpServer.getServerStatus(ServiceNameSwagger, httpServer.EnableSwaggerUI),
+		XtermURL: httpServer.getServerURL(
+			ServiceNameXterm,
+			httpServer.EnableXterm,
+			fmt.Sprintf("http://%s/xterm", request.Host),
+		),
+		XtermStatus: httpServer.getServerStatus(ServiceNameXterm, httpServer.EnableXterm),
+		Services:    httpServer.getConsoleServices(request),
 	}
 
-	writer.Header().Set("Content-Type", "text/html")
-
-	filePath := "static/templates" + request.RequestURI
-	httpServer.populateStaticTemplate(writer, request, filePath, templateVariables)
+	httpServer.populateStaticTemplate(writer, request, request.URL.Path, templateVariables)
 }
 
 func outputln(message ...any) {
@@ -1,8 +1,21 @@
 package httpserver_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +24,13 @@ import (
 	"github.com/senzing-garage/go-rest-api-service/senzingrestservice"
 	"github.com/senzing-garage/serve-http/httpserver"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // ----------------------------------------------------------------------------
@@ -25,6 +45,1206 @@ func TestBasicHTTPServer_Serve(test *testing.T) {
 	require.NoError(test, err)
 }
 
+// ----------------------------------------------------------------------------
+// Test public functions
+// ----------------------------------------------------------------------------
+
+func TestBasicHTTPServer_Handler_accessControl(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	allowedCIDRs, serviceAllowedCIDRs, err := httpserver.ParseCIDRs([]string{"192.0.2.0/24", "xterm=10.0.0.0/8"})
+	require.NoError(test, err)
+	deniedCIDRs, _, err := httpserver.ParseCIDRs([]string{"192.0.2.7"})
+	require.NoError(test, err)
+	httpServer.AllowedCIDRs = allowedCIDRs
+	httpServer.DeniedCIDRs = deniedCIDRs
+	httpServer.ServiceAllowedCIDRs = serviceAllowedCIDRs
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	testCases := []struct {
+		remoteAddr string
+		target     string
+		expected   int
+	}{
+		{remoteAddr: "192.0.2.1:1234", target: "/site/overview.html", expected: http.StatusOK},
+		{remoteAddr: "192.0.2.7:1234", target: "/site/overview.html", expected: http.StatusForbidden},
+		{remoteAddr: "198.51.100.1:1234", target: "/site/overview.html", expected: http.StatusForbidden},
+		{remoteAddr: "192.0.2.1:1234", target: "/xterm/readiness", expected: http.StatusForbidden},
+		{remoteAddr: "10.1.2.3:1234", target: "/xterm/readiness", expected: http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+		request.RemoteAddr = testCase.remoteAddr
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		require.Equal(test, testCase.expected, response.Code, testCase)
+	}
+
+	_, _, err = httpserver.ParseCIDRs([]string{"xterm=10.0.0.0/33"})
+	require.Error(test, err)
+
+	_, httpServer.ServiceDeniedCIDRs, err = httpserver.ParseCIDRs([]string{"xtrem=10.0.0.0/8"})
+	require.NoError(test, err)
+	require.ErrorContains(test, httpServer.Validate(), "ServiceDeniedCIDRs names an unknown service xtrem")
+}
+
+func TestBasicHTTPServer_Handler_bodyLimit(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.MaxRequestBodyBytes = map[string]int64{"api/search": 16}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	body := strings.NewReader(strings.Repeat("x", 65*1024))
+	request := httptest.NewRequest(http.MethodPost, "/site/overview.html", body)
+	request.Header.Set("X-Request-Id", "test-request-1")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusRequestEntityTooLarge, response.Code)
+	require.Equal(test, "application/problem+json", response.Header().Get("Content-Type"))
+	require.Equal(test, "test-request-1", response.Header().Get("X-Request-Id"))
+	require.JSONEq(
+		test,
+		`{"type":"about:blank","status":413,"title":"Request Entity Too Large",`+
+			`"detail":"request body exceeds 65536 bytes","instance":"/site/overview.html",`+
+			`"requestId":"test-request-1"}`,
+		response.Body.String(),
+	)
+
+	body = strings.NewReader(`{"NAME_FULL":"Robert Smith"}`)
+	request = httptest.NewRequest(http.MethodPost, "/api/search-entities", body)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusRequestEntityTooLarge, response.Code)
+
+	// A write is a write, even to a path containing "search".
+
+	body = strings.NewReader(`{"NAME_FULL":"Robert Smith"}`)
+	request = httptest.NewRequest(http.MethodPut, "/api/data-sources/TEST/records/research-1", body)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusOK, response.Code)
+
+	_, err = httpserver.ParseMaxRequestBodyBytes([]string{"console=-1"})
+	require.Error(test, err)
+}
+
+func TestBasicHTTPServer_Handler_compression(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableCompression = true
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	testCases := []struct {
+		acceptEncoding string
+		upgrade        string
+		expected       string
+	}{
+		{acceptEncoding: "gzip, deflate", expected: "gzip"},
+		{acceptEncoding: "gzip;q=0.5, zstd", expected: "zstd"},
+		{acceptEncoding: "br, zstd, gzip", expected: "br"},
+		{acceptEncoding: "identity", expected: ""},
+		{acceptEncoding: "gzip", upgrade: "websocket", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui.css", nil)
+		request.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+
+		if len(testCase.upgrade) > 0 {
+			request.Header.Set("Connection", "Upgrade")
+			request.Header.Set("Upgrade", testCase.upgrade)
+		}
+
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		require.Equal(test, http.StatusOK, response.Code)
+		require.Equal(test, testCase.expected, response.Header().Get("Content-Encoding"), testCase)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui.css", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	reader, err := gzip.NewReader(response.Body)
+	require.NoError(test, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(test, err)
+	require.Contains(test, string(body), ".swagger-ui")
+}
+
+func TestBasicHTTPServer_Handler_cors(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.CORSAllowedOrigins = []string{"https://*.example.com"}
+	httpServer.CORSMaxAge = 600
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	request := httptest.NewRequest(http.MethodOptions, "/api/heartbeat", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusNoContent, response.Code)
+	require.Equal(test, "https://app.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	require.Contains(test, response.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+	require.Equal(test, "600", response.Header().Get("Access-Control-Max-Age"))
+
+	request.Header.Set("Origin", "https://example.org")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusForbidden, response.Code)
+	require.Empty(test, response.Header().Get("Access-Control-Allow-Origin"))
+
+	// Any origin must not be allowed credentials.
+
+	httpServer.CORSAllowCredentials = true
+	httpServer.CORSAllowedOrigins = []string{"*"}
+	require.ErrorContains(test, httpServer.Validate(), `"*" cannot be combined with CORSAllowCredentials`)
+
+	handler, err = httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, "*", response.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(test, response.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestBasicHTTPServer_Handler_securityHeaders(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.HSTSMaxAge = 3600
+	httpServer.SecurityHeaders = map[string]httpserver.SecurityHeaders{
+		httpserver.ServiceNameSwagger: {FrameOptions: "SAMEORIGIN"},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Contains(test, response.Header().Get("Content-Security-Policy"), "style-src 'self' 'nonce-")
+	require.Equal(test, "nosniff", response.Header().Get("X-Content-Type-Options"))
+	require.Empty(test, response.Header().Get("Strict-Transport-Security"))
+
+	request := httptest.NewRequest(http.MethodGet, "/swagger/", nil)
+	request.Header.Set("X-Forwarded-Proto", "https")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(test, "SAMEORIGIN", recorder.Header().Get("X-Frame-Options"))
+	require.Contains(test, recorder.Header().Get("Content-Security-Policy"), "style-src 'self' 'unsafe-inline'")
+	require.Empty(test, recorder.Header().Get("Strict-Transport-Security"))
+
+	// X-Forwarded-Proto is only believed from trusted proxies.
+
+	httpServer.TrustedProxies = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	handler, err = httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(test, "max-age=3600; includeSubDomains", recorder.Header().Get("Strict-Transport-Security"))
+}
+
+func TestBasicHTTPServer_RegisterService(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.RegisterService(&testService{name: "admin", routePrefix: "admin"})
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/admin/status")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, "admin: /status", response.Body.String())
+	require.Equal(test, "nosniff", response.Header().Get("X-Content-Type-Options"))
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "<td>Admin</td>")
+	require.Contains(test, response.Body.String(), `href="http://example.com/admin"`)
+	require.Contains(test, response.Body.String(), `href="http://example.com/xterm"`)
+}
+
+func TestBasicHTTPServer_RegisterService_duplicate(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.RegisterService(&testService{name: "admin", routePrefix: "swagger"})
+	_, err := httpServer.Handler(ctx)
+	require.ErrorContains(test, err, "route prefix swagger is already in use")
+}
+
+func TestBasicHTTPServer_Handler_site(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/site/overview.html?refresh=1")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "<title>Senzing Tools</title>")
+	require.Contains(test, response.Body.String(), `href="http://example.com/api"`)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/debug.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "<td>swagger</td>")
+	require.NotContains(test, response.Body.String(), "sqlite3://")
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/missing.html")
+	require.Equal(test, http.StatusNotFound, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/swagger/swagger_spec")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "example.com")
+}
+
+func TestBasicHTTPServer_Handler_templatesDirectory(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	directory := test.TempDir()
+	require.NoError(test, os.MkdirAll(filepath.Join(directory, "runbooks"), 0o700))
+	runbook := `{{.HTMLTitle}} {{version}} <a href="http://{{.RequestHost}}{{serviceURL "api"}}">{{serviceURL "x"}}</a>`
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "runbooks", "restart.html"), []byte(runbook), 0o600))
+	secrets := `[{{.AdminToken}}][{{.SenzingSettings}}][{{.BasicHTTPServer.AdminToken}}]`
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "secrets.html"), []byte(secrets), 0o600))
+	httpServer := getTestObject(ctx, test)
+	httpServer.AdminToken = "secret"
+	httpServer.TemplatesDirectory = directory
+	httpServer.Version = "1.2.3"
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/site/runbooks/restart.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, `Senzing Tools 1.2.3 <a href="http://example.com/api/"></a>`, response.Body.String())
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+
+	// Secrets are never shown.
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/secrets.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, "[][][]", response.Body.String())
+
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "broken.html"), []byte("{{.NoSuchField}}"), 0o600))
+	require.ErrorContains(test, httpServer.Validate(), "field NoSuchField does not exist")
+
+	// Pointer methods cannot be called on the template's data.
+
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "broken.html"), []byte("{{.Validate}}"), 0o600))
+	require.ErrorContains(test, httpServer.Validate(), "field Validate does not exist")
+}
+
+func TestBasicHTTPServer_Handler_staticCache(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableCompression = true
+	httpServer.StaticCacheMaxAge = 300
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, "public, max-age=300", response.Header().Get("Cache-Control"))
+	etag := response.Header().Get("Etag")
+	require.NotEmpty(test, etag)
+	require.NotContains(test, etag, "W/")
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(test, http.StatusNotModified, recorder.Code)
+	require.Empty(test, recorder.Body.String())
+
+	// Swagger UI ETags are learned from the first response, then compressed responses weaken them.
+	response = serveTestRequest(handler, http.MethodGet, "/swagger/swagger-ui.css")
+	require.Equal(test, http.StatusOK, response.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui.css", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	etag = recorder.Header().Get("Etag")
+	require.True(test, strings.HasPrefix(etag, `W/"`), etag)
+
+	request.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(test, http.StatusNotModified, recorder.Code)
+}
+
+func TestBasicHTTPServer_Handler_staticDirectory(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	directory := test.TempDir()
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "app.js"), []byte("console.log(1)"), 0o600))
+	require.NoError(test, os.WriteFile(filepath.Join(directory, ".env"), []byte("SECRET=1"), 0o600))
+
+	// Layered over the embedded assets.
+
+	httpServer := getTestObject(ctx, test)
+	httpServer.StaticCacheMaxAge = 300
+	httpServer.StaticDirectory = directory
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/app.js")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, "text/javascript; charset=utf-8", response.Header().Get("Content-Type"))
+	require.Equal(test, "public, max-age=300", response.Header().Get("Cache-Control"))
+	require.Equal(test, "console.log(1)", response.Body.String())
+
+	response = serveTestRequest(handler, http.MethodGet, "/")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.NotEmpty(test, response.Header().Get("Etag"))
+
+	response = serveTestRequest(handler, http.MethodGet, "/.env")
+	require.Equal(test, http.StatusNotFound, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/entities/42")
+	require.Equal(test, http.StatusNotFound, response.Code)
+
+	// Symbolic links out of the directory are not followed.
+
+	outside := filepath.Join(test.TempDir(), "outside.txt")
+	require.NoError(test, os.WriteFile(outside, []byte("SECRET=2"), 0o600))
+	require.NoError(test, os.Symlink(outside, filepath.Join(directory, "outside.txt")))
+
+	response = serveTestRequest(handler, http.MethodGet, "/outside.txt")
+	require.Equal(test, http.StatusNotFound, response.Code)
+	require.NotContains(test, response.Body.String(), "SECRET=2")
+
+	// A single-page application replacing the embedded assets.
+
+	require.NoError(test, os.WriteFile(filepath.Join(directory, "index.html"), []byte("<div id=app>"), 0o600))
+
+	httpServer = getTestObject(ctx, test)
+	httpServer.StaticDirectory = directory
+	httpServer.StaticDirectoryExclusive = true
+	httpServer.StaticSPAFallback = true
+	handler, err = httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	for _, target := range []string{"/", "/entities/42"} {
+		response = serveTestRequest(handler, http.MethodGet, target)
+		require.Equal(test, http.StatusOK, response.Code, target)
+		require.Equal(test, "no-cache", response.Header().Get("Cache-Control"), target)
+		require.Equal(test, "<div id=app>", response.Body.String(), target)
+	}
+
+	response = serveTestRequest(handler, http.MethodGet, "/missing.js")
+	require.Equal(test, http.StatusNotFound, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+}
+
+func TestBasicHTTPServer_Handler_middlewares(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.Middlewares = []httpserver.Middleware{
+		testMiddleware("X-Trace", "global-1"),
+		testMiddleware("X-Trace", "global-2"),
+	}
+	httpServer.ServiceMiddlewares = map[string][]httpserver.Middleware{
+		httpserver.ServiceNameSwagger: {
+			testMiddleware("X-Trace", "swagger"),
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+					if len(request.Header.Get("Authorization")) == 0 {
+						http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+						return
+					}
+
+					next.ServeHTTP(writer, request)
+				})
+			},
+		},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, []string{"global-1", "global-2"}, response.Header().Values("X-Trace"))
+
+	response = serveTestRequest(handler, http.MethodGet, "/swagger/")
+	require.Equal(test, http.StatusUnauthorized, response.Code)
+	require.Equal(test, []string{"global-1", "global-2", "swagger"}, response.Header().Values("X-Trace"))
+
+	request := httptest.NewRequest(http.MethodGet, "/swagger/", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(test, http.StatusOK, recorder.Code)
+}
+
+func TestBasicHTTPServer_Handler_recovery(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableCompression = true
+	httpServer.ServiceMiddlewares = map[string][]httpserver.Middleware{
+		httpserver.ServiceNameSwagger: {
+			func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					panic("test panic")
+				})
+			},
+		},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/swagger/")
+	require.Equal(test, http.StatusInternalServerError, response.Code)
+	require.Equal(test, "application/problem+json", response.Header().Get("Content-Type"))
+	require.NotContains(test, response.Body.String(), "test panic")
+	require.Contains(test, response.Body.String(), `"requestId":"`+response.Header().Get("X-Request-Id")+`"`)
+
+	// A compressed response must not be committed as a 200 while the panic unwinds.
+
+	request := httptest.NewRequest(http.MethodGet, "/swagger/", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusInternalServerError, response.Code)
+	require.Empty(test, response.Header().Get("Content-Encoding"))
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+}
+
+func TestBasicHTTPServer_Handler_apiInstances(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.APIInstances = []httpserver.APIInstance{
+		{GrpcDialOptions: nil, GrpcTarget: "localhost:8261", Name: "sales"},
+	}
+	httpServer.SecurityHeaders = map[string]httpserver.SecurityHeaders{
+		httpserver.ServiceNameAPI: {FrameOptions: "SAMEORIGIN"},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/api/sales/swagger_spec")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "http://example.com/api/sales")
+
+	// Per-service settings of an instance fall back to those of the Senzing REST API.
+
+	require.Equal(test, "SAMEORIGIN", response.Header().Get("X-Frame-Options"))
+
+	// Route class settings, such as the larger body limit of "api/write", also apply to instances.
+
+	request := httptest.NewRequest(http.MethodPost, "/api/sales/bulk-data/load", bytes.NewReader(make([]byte, 2<<20)))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.NotEqual(test, http.StatusRequestEntityTooLarge, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), "Senzing API Server (sales)")
+	require.Contains(test, response.Body.String(), "http://example.com/api/sales")
+
+	// Only one Senzing REST API can use the local Senzing engine.
+
+	localInstance := httpserver.APIInstance{Name: "hr", SenzingSettings: httpServer.SenzingSettings}
+	httpServer.APIInstances = []httpserver.APIInstance{{Name: "search"}, {Name: "entities"}, localInstance}
+	err = httpServer.Validate()
+	require.ErrorContains(test, err, "API instance name search is a route class")
+	require.ErrorContains(test, err, "API instance name entities is a path of the Senzing REST API")
+	require.ErrorContains(test, err, "API instance search needs SenzingSettings or a GrpcTarget")
+	require.ErrorContains(test, err, "only one Senzing REST API can use SenzingSettings")
+
+	httpServer.APIInstances = []httpserver.APIInstance{localInstance}
+	httpServer.GrpcTarget = "localhost:8261"
+	err = httpServer.Validate()
+	require.NotContains(test, err.Error(), "API instance")
+	require.NotContains(test, err.Error(), "SenzingSettings")
+}
+
+func TestBasicHTTPServer_Handler_errorPages(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.ServiceMiddlewares = map[string][]httpserver.Middleware{
+		httpserver.ServiceNameSwagger: {
+			func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					panic("test panic")
+				})
+			},
+		},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	serveWithAccept := func(target string, accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Accept", accept)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+
+		return response
+	}
+	browserAccept := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+	response := serveWithAccept("/no-such-page.html", browserAccept)
+	require.Equal(test, http.StatusNotFound, response.Code)
+	require.Equal(test, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+	require.Contains(test, response.Body.String(), "404 Not Found")
+	require.Contains(test, response.Body.String(), `href="/site/overview.html"`)
+	require.Contains(test, response.Body.String(), response.Header().Get("X-Request-Id"))
+
+	response = serveWithAccept("/no-such-page.html", "application/json")
+	require.Equal(test, http.StatusNotFound, response.Code)
+	require.Equal(test, "application/problem+json", response.Header().Get("Content-Type"))
+	require.Contains(test, response.Body.String(), `"status":404`)
+
+	response = serveWithAccept("/site/no-such-page.html", browserAccept)
+	require.Equal(test, http.StatusNotFound, response.Code)
+	require.Equal(test, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+
+	response = serveWithAccept("/swagger/", browserAccept)
+	require.Equal(test, http.StatusInternalServerError, response.Code)
+	require.Contains(test, response.Body.String(), "500 Internal Server Error")
+	require.NotContains(test, response.Body.String(), "test panic")
+
+	response = serveWithAccept("/swagger/", "*/*")
+	require.Equal(test, http.StatusInternalServerError, response.Code)
+	require.Equal(test, "application/problem+json", response.Header().Get("Content-Type"))
+}
+
+func TestBasicHTTPServer_Handler_h2c(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableH2C = true
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	frontDoor := httptest.NewServer(handler)
+	test.Cleanup(frontDoor.Close)
+
+	// Prior knowledge: the client starts with the HTTP/2 preface.
+
+	protocols := &http.Protocols{}
+	protocols.SetUnencryptedHTTP2(true)
+	transport := &http.Transport{Protocols: protocols}
+	test.Cleanup(transport.CloseIdleConnections)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, frontDoor.URL+"/site/overview.html", nil)
+	require.NoError(test, err)
+	response, err := transport.RoundTrip(request)
+	require.NoError(test, err)
+	_ = response.Body.Close()
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, 2, response.ProtoMajor)
+
+	// Upgrade: the client asks to switch an HTTP/1.1 request to HTTP/2, and is answered over HTTP/2.
+
+	connection, err := (&net.Dialer{}).DialContext(ctx, "tcp", frontDoor.Listener.Addr().String())
+	require.NoError(test, err)
+
+	defer connection.Close()
+
+	require.NoError(test, connection.SetDeadline(time.Now().Add(10*time.Second)))
+	_, err = io.WriteString(connection, "GET /site/overview.html HTTP/1.1\r\nHost: localhost\r\n"+
+		"Connection: Upgrade, HTTP2-Settings\r\nUpgrade: h2c\r\nHTTP2-Settings: \r\n\r\n")
+	require.NoError(test, err)
+
+	reader := bufio.NewReader(connection)
+	upgradeResponse, err := http.ReadResponse(reader, nil)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusSwitchingProtocols, upgradeResponse.StatusCode)
+
+	_, err = io.WriteString(connection, http2.ClientPreface)
+	require.NoError(test, err)
+
+	framer := http2.NewFramer(connection, reader)
+	framer.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+	require.NoError(test, framer.WriteSettings())
+
+	for {
+		frame, err := framer.ReadFrame()
+		require.NoError(test, err)
+
+		headers, isHeaders := frame.(*http2.MetaHeadersFrame)
+		if isHeaders && headers.StreamID == 1 {
+			require.Equal(test, "200", headers.PseudoValue("status"))
+
+			break
+		}
+	}
+}
+
+func TestBasicHTTPServer_Handler_logLevel(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.AdminToken = "secret"
+	httpServer.LogLevelName = "WARN"
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestAdminRequest(handler, http.MethodGet, "/admin/log-level", "")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.JSONEq(test, `{"logLevel":"WARN"}`, response.Body.String())
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/log-level", `{"logLevel":"debug"}`)
+	require.Equal(test, http.StatusOK, response.Code)
+
+	var status httpserver.LogLevelStatus
+
+	require.NoError(test, json.Unmarshal(response.Body.Bytes(), &status))
+	require.Equal(test, "DEBUG", status.LogLevel)
+	require.WithinDuration(test, time.Now().Add(15*time.Minute), status.RevertAt, time.Minute)
+
+	// The level set is restored to LogLevelName after a while.
+
+	response = serveTestAdminRequest(
+		handler,
+		http.MethodPut,
+		"/admin/log-level",
+		`{"logLevel":"TRACE","revertAfterSeconds":1}`,
+	)
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Eventually(test, func() bool {
+		response := serveTestAdminRequest(handler, http.MethodGet, "/admin/log-level", "")
+
+		return response.Body.String() == `{"logLevel":"WARN"}`+"\n"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/log-level", `{"logLevel":"LOUD"}`)
+	require.Equal(test, http.StatusBadRequest, response.Code)
+}
+
+func TestBasicHTTPServer_Handler_serviceSwitches(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.AdminToken = "secret"
+	httpServer.EnableXterm = true
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestAdminRequest(handler, http.MethodGet, "/admin/services", "")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), `{"enabled":true,"name":"xterm"}`)
+	require.NotContains(test, response.Body.String(), `"name":"admin"`)
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/services/xterm", `{"enabled":false}`)
+	require.Equal(test, http.StatusOK, response.Code)
+	require.JSONEq(test, `{"enabled":false,"name":"xterm"}`, response.Body.String())
+
+	response = serveTestRequest(handler, http.MethodGet, "/xterm/")
+	require.Equal(test, http.StatusServiceUnavailable, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.NotContains(test, response.Body.String(), `href="http://example.com/xterm"`)
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/services/xterm", `{"enabled":true}`)
+	require.Equal(test, http.StatusOK, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Contains(test, response.Body.String(), `href="http://example.com/xterm"`)
+
+	// Services that were not enabled at startup, and the admin API itself, cannot be switched.
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/services/grpc-web", `{"enabled":true}`)
+	require.Equal(test, http.StatusNotFound, response.Code)
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/services/admin", `{"enabled":false}`)
+	require.Equal(test, http.StatusNotFound, response.Code)
+}
+
+func TestBasicHTTPServer_Handler_maintenance(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.AdminToken = "secret"
+	httpServer.EnableXterm = true
+	httpServer.Maintenance = true
+	httpServer.MaintenanceDisablesXterm = true
+	httpServer.MaintenanceMessage = "Reloading the repository."
+	httpServer.MaintenanceRetryAfter = 60
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	// Writes are refused.  Searches and reads are not.
+
+	response := serveTestRequest(handler, http.MethodPost, "/api/data-sources")
+	require.Equal(test, http.StatusServiceUnavailable, response.Code)
+	require.Equal(test, "60", response.Header().Get("Retry-After"))
+	require.Contains(test, response.Body.String(), "Reloading the repository.")
+
+	response = serveTestRequest(handler, http.MethodDelete, "/api/data-sources/TEST/records/research-1")
+	require.Equal(test, http.StatusServiceUnavailable, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/api/heartbeat")
+	require.Equal(test, http.StatusOK, response.Code)
+
+	response = serveTestRequest(handler, http.MethodPost, "/api/search-entities")
+	require.Equal(test, http.StatusOK, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/xterm/")
+	require.Equal(test, http.StatusServiceUnavailable, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Contains(test, response.Body.String(), `role="status">Reloading the repository.</div>`)
+
+	// The admin API needs the admin token.
+
+	response = serveTestRequest(handler, http.MethodGet, "/admin/maintenance")
+	require.Equal(test, http.StatusUnauthorized, response.Code)
+
+	response = serveTestAdminRequest(handler, http.MethodPut, "/admin/maintenance", `{"enabled":false}`)
+	require.Equal(test, http.StatusOK, response.Code)
+	require.JSONEq(test, `{"enabled":false}`, response.Body.String())
+
+	response = serveTestRequest(handler, http.MethodPost, "/api/data-sources")
+	require.NotEqual(test, http.StatusServiceUnavailable, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.NotContains(test, response.Body.String(), `class="maintenance"`)
+}
+
+func TestBasicHTTPServer_Handler_mirror(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	shadowHeaders := make(chan http.Header, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		shadowHeaders <- request.Header.Clone()
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(writer, `{"shadowPath":"`+request.URL.Path+`"}`)
+	}))
+	test.Cleanup(shadow.Close)
+
+	reportFile := filepath.Join(test.TempDir(), "mirror.jsonl")
+	httpServer := getTestObject(ctx, test)
+	httpServer.MirrorPercent = 100
+	httpServer.MirrorReportFile = reportFile
+	httpServer.MirrorURL = shadow.URL + "/shadow"
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	request := httptest.NewRequestWithContext(ctx, http.MethodGet, "/api/heartbeat", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("Cookie", "session=secret")
+	request.Header.Set("Proxy-Authorization", "Basic c2VjcmV0")
+	request.Header.Set("X-Client", "test")
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusOK, response.Code)
+
+	// The client's credentials are not copied to the shadow backend.
+
+	headers := <-shadowHeaders
+	require.Equal(test, "test", headers.Get("X-Client"))
+	require.Empty(test, headers.Get("Authorization"))
+	require.Empty(test, headers.Get("Cookie"))
+	require.Empty(test, headers.Get("Proxy-Authorization"))
+
+	var record struct {
+		Differences  []string `json:"differences"`
+		Method       string   `json:"method"`
+		Path         string   `json:"path"`
+		RequestID    string   `json:"requestId"`
+		ShadowStatus int      `json:"shadowStatus"`
+	}
+
+	require.Eventually(test, func() bool {
+		report, err := os.ReadFile(reportFile)
+
+		return err == nil && json.Unmarshal(report, &record) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(test, http.MethodGet, record.Method)
+	require.Equal(test, "/api/heartbeat", record.Path)
+	require.Equal(test, response.Header().Get("X-Request-Id"), record.RequestID)
+	require.Equal(test, http.StatusOK, record.ShadowStatus)
+	require.Contains(test, record.Differences, "shadowPath")
+
+	httpServer.MirrorPercent = 101
+	httpServer.MirrorReportFile = ""
+	err = httpServer.Validate()
+	require.ErrorContains(test, err, "MirrorPercent 101 is not between 0 and 100")
+	require.ErrorContains(test, err, "needs a MirrorReportFile")
+}
+
+func TestBasicHTTPServer_Handler_reverseProxy(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	upstream := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Upgrade") == "echo" {
+			connection, readWriter, err := http.NewResponseController(writer).Hijack()
+			if err != nil {
+				return
+			}
+
+			defer connection.Close()
+
+			_, _ = readWriter.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+			_, _ = readWriter.WriteString("Connection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+			_ = readWriter.Flush()
+			_, _ = io.Copy(connection, readWriter)
+
+			return
+		}
+
+		if request.URL.Path == "/base/missing" {
+			http.Error(writer, "no such legacy item", http.StatusNotFound)
+
+			return
+		}
+
+		writer.Header().Set("Server", "legacy")
+		_, _ = io.WriteString(
+			writer,
+			request.URL.Path+" "+request.Header.Get("X-Forwarded-Prefix")+" "+request.Header.Get("X-Team"),
+		)
+	}))
+	test.Cleanup(upstream.Close)
+
+	httpServer := getTestObject(ctx, test)
+	httpServer.ReverseProxies = []httpserver.ReverseProxy{
+		{
+			Name:            "legacy",
+			RequestHeaders:  map[string]string{"X-Team": "entity"},
+			ResponseHeaders: map[string]string{"Server": ""},
+			UpstreamURL:     upstream.URL + "/base",
+		},
+		{Name: "down", UpstreamURL: "http://127.0.0.1:1"},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/legacy/items")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Equal(test, "/base/items /legacy entity", response.Body.String())
+	require.Empty(test, response.Header().Get("Server"))
+
+	response = serveTestRequest(handler, http.MethodGet, "/down/items")
+	require.Equal(test, http.StatusBadGateway, response.Code)
+	require.Equal(test, "application/problem+json", response.Header().Get("Content-Type"))
+
+	// The upstream's errors are passed through, not replaced by error pages.
+
+	request := httptest.NewRequest(http.MethodGet, "/legacy/missing", nil)
+	request.Header.Set("Accept", "text/html")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusNotFound, response.Code)
+	require.Equal(test, "text/plain; charset=utf-8", response.Header().Get("Content-Type"))
+	require.Equal(test, "no such legacy item\n", response.Body.String())
+
+	response = serveTestRequest(handler, http.MethodGet, "/legacy/missing")
+	require.Equal(test, "no such legacy item\n", response.Body.String())
+
+	// Only the "down" upstream fails its health check.
+
+	require.Eventually(test, func() bool {
+		response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+
+		return strings.Count(response.Body.String(), `fill="orange"`) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Websocket and other upgrades are passed through.
+
+	frontDoor := httptest.NewServer(handler)
+	test.Cleanup(frontDoor.Close)
+
+	request, err = http.NewRequestWithContext(ctx, http.MethodGet, frontDoor.URL+"/legacy/echo", nil)
+	require.NoError(test, err)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "echo")
+	upgradeResponse, err := http.DefaultClient.Do(request)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusSwitchingProtocols, upgradeResponse.StatusCode)
+
+	connection, isReadWriteCloser := upgradeResponse.Body.(io.ReadWriteCloser)
+	require.True(test, isReadWriteCloser)
+
+	defer connection.Close()
+
+	_, err = io.WriteString(connection, "ping")
+	require.NoError(test, err)
+
+	echo := make([]byte, 4)
+	_, err = io.ReadFull(connection, echo)
+	require.NoError(test, err)
+	require.Equal(test, "ping", string(echo))
+
+	_, err = httpserver.ParseReverseProxies([]string{"legacy=ftp://example.com"})
+	require.Error(test, err)
+}
+
+func TestBasicHTTPServer_Handler_grpcBalancing(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	grpcTargets := []string{}
+
+	for _, name := range []string{"first", "second"} {
+		listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", "127.0.0.1:0")
+		require.NoError(test, err)
+
+		grpcServer := newTestGrpcServer(name)
+
+		go func() { _ = grpcServer.Serve(listener) }()
+
+		test.Cleanup(grpcServer.Stop)
+
+		grpcTargets = append(grpcTargets, listener.Addr().String())
+	}
+
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableAll = false
+	httpServer.EnableGrpcWeb = true
+	httpServer.GrpcDialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	httpServer.GrpcTargets = append(grpcTargets, "127.0.0.1:1")
+	httpServer.GrpcWebURLRoutePrefix = "grpc-web"
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	frontDoor := httptest.NewServer(handler)
+	test.Cleanup(frontDoor.Close)
+
+	// The target without a server fails its health checks and is ejected.
+
+	require.Eventually(test, func() bool {
+		body := serveTestRequest(handler, http.MethodGet, "/site/overview.html").Body.String()
+
+		return strings.Contains(body, "<td>127.0.0.1:1</td>\n      <td>ejected</td>")
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// Calls are balanced across the other targets.
+
+	servers := map[string]bool{}
+	request := []byte{0, 0, 0, 0, 2, 'h', 'i'}
+
+	require.Eventually(test, func() bool {
+		response := postTestGrpcWeb(ctx, test, frontDoor.URL+"/grpc-web/test.Echo/Say", "application/grpc-web", request)
+		require.Contains(test, response.Body, "grpc-status: 0\r\n")
+		servers[response.Header.Get("X-Server")] = true
+
+		return servers["first"] && servers["second"]
+	}, 10*time.Second, 10*time.Millisecond)
+
+	httpServer.GrpcLoadBalancing = "random"
+	require.ErrorContains(test, httpServer.Validate(), "GrpcLoadBalancing random")
+
+	httpServer.GrpcTarget = "localhost:8261"
+	require.ErrorContains(test, httpServer.Validate(), "GrpcTarget and GrpcTargets cannot both be set")
+}
+
+func TestBasicHTTPServer_Handler_grpcWeb(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(test, err)
+
+	grpcServer := newTestGrpcServer("echo")
+
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	test.Cleanup(grpcServer.Stop)
+
+	httpServer := getTestObject(ctx, test)
+	httpServer.EnableAll = false
+	httpServer.EnableGrpcWeb = true
+	httpServer.GrpcDialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	httpServer.GrpcTarget = listener.Addr().String()
+	httpServer.GrpcWebURLRoutePrefix = "grpc-web"
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	frontDoor := httptest.NewServer(handler)
+	test.Cleanup(frontDoor.Close)
+
+	request := []byte{0, 0, 0, 0, 2, 'h', 'i'}
+	dataFrame := string(request)
+
+	response := postTestGrpcWeb(ctx, test, frontDoor.URL+"/grpc-web/test.Echo/Say", "application/grpc-web", request)
+	require.Equal(test, "header", response.Header.Get("X-Echo"))
+	require.True(test, strings.HasPrefix(response.Body, dataFrame+dataFrame+"\x80"))
+	require.Contains(test, response.Body, "grpc-status: 0\r\n")
+	require.Contains(test, response.Body, "x-echo: trailer\r\n")
+
+	// gRPC-Web text is base64 encoded in both directions.
+
+	response = postTestGrpcWeb(
+		ctx,
+		test,
+		frontDoor.URL+"/grpc-web/test.Echo/Say",
+		"application/grpc-web-text",
+		[]byte(base64.StdEncoding.EncodeToString(request)),
+	)
+	require.True(test, strings.HasPrefix(response.Body, base64.StdEncoding.EncodeToString(request)))
+
+	response = postTestGrpcWeb(
+		ctx,
+		test,
+		frontDoor.URL+"/grpc-web/test.Echo/Say",
+		"application/grpc-web-text",
+		[]byte(base64.StdEncoding.EncodeToString(request[:4])+"\n"+base64.StdEncoding.EncodeToString(request[4:])),
+	)
+	require.True(test, strings.HasPrefix(response.Body, base64.StdEncoding.EncodeToString(request)))
+
+	// Only the frame length from the header is read, and only up to the body limit.
+
+	response = postTestGrpcWeb(
+		ctx,
+		test,
+		frontDoor.URL+"/grpc-web/test.Echo/Say",
+		"application/grpc-web",
+		append([]byte{0, 0xff, 0xff, 0xff, 0xff}, make([]byte, 7<<20)...),
+	)
+	require.Equal(test, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+	response = postTestGrpcWeb(
+		ctx,
+		test,
+		frontDoor.URL+"/grpc-web/test.Echo/Say",
+		"application/grpc-web",
+		[]byte{0, 0, 0, 0, 3, 'h', 'i'},
+	)
+	require.Equal(test, http.StatusBadRequest, response.StatusCode)
+
+	response = postTestGrpcWeb(ctx, test, frontDoor.URL+"/grpc-web/test.Echo/Fail", "application/grpc-web", request)
+	require.Contains(test, response.Body, "grpc-status: 5\r\n")
+	require.Contains(test, response.Body, "grpc-message: no such entity\r\n")
+
+	response = postTestGrpcWeb(ctx, test, frontDoor.URL+"/grpc-web/test.Echo/Say", "application/json", request)
+	require.Equal(test, http.StatusUnsupportedMediaType, response.StatusCode)
+}
+
+func TestBasicHTTPServer_Handler_rateLimit(test *testing.T) {
+	test.Parallel()
+	ctx := test.Context()
+	httpServer := getTestObject(ctx, test)
+	httpServer.AdminToken = "secret"
+	httpServer.RateLimitKeyHeader = "X-Api-Key"
+	httpServer.RateLimits = map[string]httpserver.RateLimit{
+		httpserver.ServiceNameConsole: {Burst: 1, RequestsPerSecond: 0.01},
+	}
+	handler, err := httpServer.Handler(ctx)
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusOK, response.Code)
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusTooManyRequests, response.Code)
+	require.Equal(test, "100", response.Header().Get("Retry-After"))
+
+	// A new key header value does not get a new bucket, because the client IP address is limited too.
+
+	request := httptest.NewRequest(http.MethodGet, "/site/overview.html", nil)
+	request.Header.Set("X-Api-Key", "fresh")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	require.Equal(test, http.StatusTooManyRequests, response.Code)
+
+	// Buckets are reported through the admin API, which needs the admin token.
+
+	response = serveTestAdminRequest(handler, http.MethodGet, "/admin/ratelimits", "")
+	require.Equal(test, http.StatusOK, response.Code)
+	require.Contains(test, response.Body.String(), `"route":"console"`)
+	require.Contains(test, response.Body.String(), `"client":"X-Api-Key:`)
+	require.NotContains(test, response.Body.String(), "fresh")
+
+	// Many key header values from one client do not evict the buckets of other clients' IP addresses.
+
+	for index := range 10001 {
+		request = httptest.NewRequest(http.MethodGet, "/site/overview.html", nil)
+		request.RemoteAddr = "198.51.100.1:1234"
+		request.Header.Set("X-Api-Key", strconv.Itoa(index))
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	response = serveTestRequest(handler, http.MethodGet, "/site/overview.html")
+	require.Equal(test, http.StatusTooManyRequests, response.Code)
+}
+
+func TestNew(test *testing.T) {
+	test.Parallel()
+	senzingSettings, err := settings.BuildSimpleSettingsUsingEnvVars()
+	require.NoError(test, err)
+
+	httpServer, err := httpserver.New(
+		httpserver.WithEnableAll(true),
+		httpserver.WithSenzing(senzingSettings, "Test HTTP Server", 0),
+		httpserver.WithAvoidServing(true),
+		httpserver.WithTtyOnly(true),
+	)
+	require.NoError(test, err)
+	require.Equal(test, 8260, httpServer.ServerPort)
+	require.Equal(test, "api", httpServer.APIUrlRoutePrefix)
+
+	handler, err := httpServer.Handler(test.Context())
+	require.NoError(test, err)
+
+	response := serveTestRequest(handler, http.MethodGet, "/swagger/")
+	require.Equal(test, http.StatusOK, response.Code)
+}
+
+func TestNew_invalid(test *testing.T) {
+	test.Parallel()
+
+	httpServer, err := httpserver.New(
+		httpserver.WithAddress("0.0.0.0", 70000),
+		httpserver.WithEnableXterm(true),
+		httpserver.WithXterm(""),
+		httpserver.WithLogLevelName("LOUD"),
+		httpserver.WithRateLimits(map[string]httpserver.RateLimit{"api": {Burst: 0, RequestsPerSecond: 1}}, ""),
+	)
+	require.Nil(test, httpServer)
+	require.ErrorContains(test, err, "ServerPort 70000")
+	require.ErrorContains(test, err, "XtermCommand is empty")
+	require.ErrorContains(test, err, "need SenzingSettings or a GrpcTarget")
+	require.ErrorContains(test, err, "LogLevelName LOUD")
+	require.ErrorContains(test, err, "rate limit api")
+
+	var joined interface{ Unwrap() []error }
+
+	require.ErrorAs(test, err, &joined)
+	require.Len(test, joined.Unwrap(), 5)
+}
+
+func TestParseRateLimits(test *testing.T) {
+	test.Parallel()
+
+	actual, err := httpserver.ParseRateLimits([]string{"api=10:20", "api/search=0.5:2"})
+	require.NoError(test, err)
+	require.Equal(test, map[string]httpserver.RateLimit{
+		"api":        {Burst: 20, RequestsPerSecond: 10},
+		"api/search": {Burst: 2, RequestsPerSecond: 0.5},
+	}, actual)
+
+	for _, specification := range []string{"api", "api=10", "api=x:1", "api=10:0", "=1:1"} {
+		_, err = httpserver.ParseRateLimits([]string{specification})
+		require.Error(test, err, specification)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Test private functions
 // ----------------------------------------------------------------------------
@@ -33,7 +1253,7 @@ func TestBasicHTTPServer_Serve(test *testing.T) {
 // 	_ = test
 // 	ctx := context.TODO()
 // 	httpServer := getTestObject(ctx, test)
-// 	actual := httpServer.getServerStatus(true)
+// 	actual := httpServer.getServerStatus(ServiceNameAPI, true)
 // 	assert.Equal(test, "green", actual)
 // }
 
@@ -42,7 +1262,7 @@ func TestBasicHTTPServer_Serve(test *testing.T) {
 // 	ctx := context.TODO()
 // 	expected := "http://expected"
 // 	httpServer := getTestObject(ctx, test)
-// 	actual := httpServer.getServerURL(true, expected)
+// 	actual := httpServer.getServerURL(ServiceNameAPI, true, expected)
 // 	assert.Equal(test, expected, actual)
 // }
 
@@ -115,3 +1335,160 @@ func getTestObject(ctx context.Context, t *testing.T) *httpserver.BasicHTTPServe
 
 	return result
 }
+
+// A gRPC server that echoes each message of a "/test.Echo/Say" call twice, naming itself in an "x-server" header.
+// "/test.Echo/Fail" is not found.
+func newTestGrpcServer(name string) *grpc.Server {
+	return grpc.NewServer(
+		grpc.ForceServerCodec(testBytesCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method == "/test.Echo/Fail" {
+				return status.Error(codes.NotFound, "no such entity")
+			}
+
+			var message []byte
+
+			err := stream.RecvMsg(&message)
+			if err != nil {
+				return err
+			}
+
+			_ = stream.SetHeader(metadata.Pairs("x-echo", "header", "x-server", name))
+			stream.SetTrailer(metadata.Pairs("x-echo", "trailer"))
+
+			for range 2 {
+				err = stream.SendMsg(&message)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+	)
+}
+
+// Post a gRPC-Web request, returning the whole response body.
+func postTestGrpcWeb(
+	ctx context.Context,
+	t *testing.T,
+	target string,
+	contentType string,
+	body []byte,
+) testGrpcWebResponse {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", contentType)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return testGrpcWebResponse{Body: string(responseBody), Header: response.Header, StatusCode: response.StatusCode}
+}
+
+func serveTestRequest(handler http.Handler, method string, target string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, nil)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	return response
+}
+
+// Serve a request to the admin API, bearing the admin token of the tests.
+func serveTestAdminRequest(
+	handler http.Handler,
+	method string,
+	target string,
+	body string,
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer secret")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	return response
+}
+
+// Add a header value, so that the order middlewares run in can be checked.
+func testMiddleware(headerName string, value string) httpserver.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Add(headerName, value)
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Test services
+// ----------------------------------------------------------------------------
+
+type testGrpcWebResponse struct {
+	Body       string
+	Header     http.Header
+	StatusCode int
+}
+
+// Passes gRPC messages through as bytes, so that the test gRPC server needs no generated code.
+type testBytesCodec struct{}
+
+func (testBytesCodec) Marshal(value any) ([]byte, error) {
+	message, _ := value.(*[]byte)
+
+	return *message, nil
+}
+
+func (testBytesCodec) Name() string {
+	return "proto"
+}
+
+func (testBytesCodec) Unmarshal(data []byte, value any) error {
+	message, _ := value.(*[]byte)
+	*message = bytes.Clone(data)
+
+	return nil
+}
+
+type testService struct {
+	name        string
+	routePrefix string
+}
+
+func (service *testService) Banner(serviceURL string) string {
+	return "Serving admin at " + serviceURL
+}
+
+func (service *testService) ConsoleCard() httpserver.ConsoleCard {
+	return httpserver.ConsoleCard{
+		CommandLineOption:   "",
+		EnvironmentVariable: "",
+		Title:               "Admin",
+	}
+}
+
+func (service *testService) Handler(ctx context.Context) (http.Handler, error) {
+	_ = ctx
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, service.name+": "+request.URL.Path)
+	}), nil
+}
+
+func (service *testService) IsEnabled() bool {
+	return true
+}
+
+func (service *testService) Name() string {
+	return service.name
+}
+
+func (service *testService) RoutePrefix() string {
+	return service.routePrefix
+}
@@ -0,0 +1,271 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// LogLevelStatus is the log level of the running server, as reported and set by the admin API.
+type LogLevelStatus struct {
+	LogLevel           string    `json:"logLevel"`                     // A LogLevelName, e.g. "DEBUG".
+	RevertAfterSeconds int       `json:"revertAfterSeconds,omitempty"` // Set only.  If 0, LogLevelRevertAfter.
+	RevertAt           time.Time `json:"revertAt,omitzero"`            // When LogLevelName is restored.
+}
+
+// A service whose log level follows that of the server.
+type logLevelSetter interface {
+	SetLogLevel(ctx context.Context, logLevelName string) error
+}
+
+/*
+Changes the log level of a running server, then restores LogLevelName after a while, so that
+a level raised to diagnose a problem is not left on.  Changes reach the request logger and the
+Senzing REST APIs.  SenzingVerboseLogging is not changed: it is fixed when the Senzing engine is
+initialized, so it needs a restart.
+*/
+type logLevelSwitch struct {
+	generation      int // Changed by every change of the log level, so that stale revert timers do nothing.
+	logLevel        *slog.LevelVar
+	logLevelSetters []logLevelSetter // Such as the Senzing REST APIs.
+	logger          *slog.Logger
+	mutex           sync.Mutex
+	revertAfter     time.Duration
+	revertAt        time.Time
+	revertTimer     *time.Timer
+	startLevel      slog.Level
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// Senzing log levels without an slog equivalent.
+const (
+	logLevelTrace = slog.LevelDebug - 4
+	logLevelFatal = slog.LevelError + 4
+	logLevelPanic = slog.LevelError + 8
+)
+
+const defaultLogLevelRevertAfter = 15 * time.Minute
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Translate an slog.Level into a Senzing log level name.  The inverse of parseLogLevelName.
+func logLevelName(level slog.Level) string {
+	switch {
+	case level <= logLevelTrace:
+		return "TRACE"
+	case level <= slog.LevelDebug:
+		return "DEBUG"
+	case level <= slog.LevelInfo:
+		return "INFO"
+	case level <= slog.LevelWarn:
+		return "WARN"
+	case level <= slog.LevelError:
+		return "ERROR"
+	case level <= logLevelFatal:
+		return "FATAL"
+	default:
+		return "PANIC"
+	}
+}
+
+// Translate a Senzing log level name (TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC) into an slog.Level.
+func parseLogLevelName(logLevelName string) (slog.Level, bool) {
+	switch strings.ToUpper(strings.TrimSpace(logLevelName)) {
+	case "TRACE":
+		return logLevelTrace, true
+	case "DEBUG":
+		return slog.LevelDebug, true
+	case "", "INFO":
+		return slog.LevelInfo, true
+	case "WARN":
+		return slog.LevelWarn, true
+	case "ERROR":
+		return slog.LevelError, true
+	case "FATAL":
+		return logLevelFatal, true
+	case "PANIC":
+		return logLevelPanic, true
+	default:
+		return slog.LevelInfo, false
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Create the logger used by request handlers.  Its level is held in httpServer.logLevel.
+func (httpServer *BasicHTTPServer) initializeLogger() {
+	level, _ := parseLogLevelName(httpServer.LogLevelName)
+	httpServer.logLevel = &slog.LevelVar{}
+	httpServer.logLevel.Set(level)
+	httpServer.logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		AddSource:   false,
+		Level:       httpServer.logLevel,
+		ReplaceAttr: nil,
+	}))
+
+	httpServer.logLevelSwitch = &logLevelSwitch{
+		generation:      0,
+		logLevel:        httpServer.logLevel,
+		logLevelSetters: nil,
+		logger:          httpServer.logger,
+		mutex:           sync.Mutex{},
+		revertAfter:     httpServer.LogLevelRevertAfter,
+		revertAt:        time.Time{},
+		revertTimer:     nil,
+		startLevel:      level,
+	}
+
+	if httpServer.logLevelSwitch.revertAfter <= 0 {
+		httpServer.logLevelSwitch.revertAfter = defaultLogLevelRevertAfter
+	}
+}
+
+// Make a service's log level follow that of the server.
+func (levelSwitch *logLevelSwitch) add(setter logLevelSetter) {
+	levelSwitch.mutex.Lock()
+	defer levelSwitch.mutex.Unlock()
+
+	levelSwitch.logLevelSetters = append(levelSwitch.logLevelSetters, setter)
+}
+
+// Set the log level of the request logger and of every logLevelSetter.  Callers hold the mutex.
+func (levelSwitch *logLevelSwitch) apply(ctx context.Context, level slog.Level) {
+	levelSwitch.logLevel.Set(level)
+
+	for _, setter := range levelSwitch.logLevelSetters {
+		err := setter.SetLogLevel(ctx, logLevelName(level))
+		if err != nil {
+			levelSwitch.logger.ErrorContext(ctx, "log level not changed", "logLevel", logLevelName(level), "error", err)
+		}
+	}
+}
+
+// Lower the log level by one step, e.g. from INFO to DEBUG, so that more is logged.
+func (levelSwitch *logLevelSwitch) increaseVerbosity(ctx context.Context) {
+	level, _ := parseLogLevelName(logLevelName(levelSwitch.logLevel.Level()))
+	if level > logLevelTrace {
+		level -= slog.LevelInfo - slog.LevelDebug
+	}
+
+	levelSwitch.set(ctx, level, 0)
+}
+
+// Restore LogLevelName, the level the server started with.
+func (levelSwitch *logLevelSwitch) reset(ctx context.Context) {
+	levelSwitch.mutex.Lock()
+	levelSwitch.restore(ctx)
+	levelSwitch.mutex.Unlock()
+
+	levelSwitch.logger.WarnContext(ctx, "log level restored", "logLevel", logLevelName(levelSwitch.startLevel))
+}
+
+// Callers hold the mutex.
+func (levelSwitch *logLevelSwitch) restore(ctx context.Context) {
+	if levelSwitch.revertTimer != nil {
+		levelSwitch.revertTimer.Stop()
+		levelSwitch.revertTimer = nil
+	}
+
+	levelSwitch.generation++
+	levelSwitch.revertAt = time.Time{}
+	levelSwitch.apply(ctx, levelSwitch.startLevel)
+}
+
+// Restore LogLevelName when a revert timer fires, unless the log level has been changed since it was started.
+func (levelSwitch *logLevelSwitch) revert(ctx context.Context, generation int) {
+	levelSwitch.mutex.Lock()
+
+	if generation != levelSwitch.generation {
+		levelSwitch.mutex.Unlock()
+
+		return
+	}
+
+	levelSwitch.restore(ctx)
+	levelSwitch.mutex.Unlock()
+
+	levelSwitch.logger.WarnContext(ctx, "log level restored", "logLevel", logLevelName(levelSwitch.startLevel))
+}
+
+// Change the log level until revertAfter has passed.  If revertAfter is 0, LogLevelRevertAfter.
+func (levelSwitch *logLevelSwitch) set(ctx context.Context, level slog.Level, revertAfter time.Duration) {
+	if level == levelSwitch.startLevel {
+		levelSwitch.reset(ctx)
+
+		return
+	}
+
+	if revertAfter <= 0 {
+		revertAfter = levelSwitch.revertAfter
+	}
+
+	levelSwitch.mutex.Lock()
+
+	if levelSwitch.revertTimer != nil {
+		levelSwitch.revertTimer.Stop()
+	}
+
+	levelSwitch.generation++
+	generation := levelSwitch.generation
+	levelSwitch.apply(ctx, level)
+	levelSwitch.revertAt = time.Now().Add(revertAfter)
+	levelSwitch.revertTimer = time.AfterFunc(revertAfter, func() {
+		levelSwitch.revert(context.WithoutCancel(ctx), generation)
+	})
+	revertAt := levelSwitch.revertAt
+	levelSwitch.mutex.Unlock()
+
+	levelSwitch.logger.WarnContext(ctx, "log level changed", "logLevel", logLevelName(level), "revertAt", revertAt)
+}
+
+func (levelSwitch *logLevelSwitch) status() LogLevelStatus {
+	levelSwitch.mutex.Lock()
+	defer levelSwitch.mutex.Unlock()
+
+	return LogLevelStatus{
+		LogLevel:           logLevelName(levelSwitch.logLevel.Level()),
+		RevertAfterSeconds: 0,
+		RevertAt:           levelSwitch.revertAt,
+	}
+}
+
+// GET reports the log level.  PUT sets it from a LogLevelStatus and reports the result.
+func (levelSwitch *logLevelSwitch) statusFunc(writer http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPut {
+		var status LogLevelStatus
+
+		err := json.NewDecoder(request.Body).Decode(&status)
+		if err != nil {
+			writeProblem(writer, request, http.StatusBadRequest, "body is not a log level status: "+err.Error())
+
+			return
+		}
+
+		level, isValid := parseLogLevelName(status.LogLevel)
+		if !isValid || len(status.LogLevel) == 0 {
+			writeProblem(writer, request, http.StatusBadRequest, "logLevel "+status.LogLevel+" is not a log level")
+
+			return
+		}
+
+		levelSwitch.set(request.Context(), level, time.Duration(status.RevertAfterSeconds)*time.Second)
+	}
+
+	writeJSON(writer, levelSwitch.status())
+}
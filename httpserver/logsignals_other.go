@@ -0,0 +1,15 @@
+//go:build !unix
+
+package httpserver
+
+import "context"
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// There is no SIGUSR1 or SIGUSR2, so the log level is only changed through the admin API.
+func (httpServer *BasicHTTPServer) watchLogLevelSignals(ctx context.Context) {
+	_ = ctx
+	_ = httpServer
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package httpserver
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Until the context is done, SIGUSR1 lowers the log level by one step and SIGUSR2 restores LogLevelName.
+func (httpServer *BasicHTTPServer) watchLogLevelSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case received := <-signals:
+				if received == syscall.SIGUSR1 {
+					httpServer.logLevelSwitch.increaseVerbosity(ctx)
+				} else {
+					httpServer.logLevelSwitch.reset(ctx)
+				}
+			}
+		}
+	}()
+}
@@ -2,6 +2,8 @@ package httpserver
 
 import (
 	"context"
+	"errors"
+	"net/http"
 )
 
 // ----------------------------------------------------------------------------
@@ -12,3 +14,26 @@ import (
 type HTTPServer interface {
 	Serve(ctx context.Context) error
 }
+
+// A Service is a web application that BasicHTTPServer mounts at "/<RoutePrefix>/",
+// lists on the Console overview, and reports at startup.
+type Service interface {
+	Banner(serviceURL string) string                   // The startup message for the service at serviceURL.
+	ConsoleCard() ConsoleCard                          // How the service is listed on the Console overview.
+	Handler(ctx context.Context) (http.Handler, error) // Receives requests with the route prefix stripped.
+	IsEnabled() bool
+	Name() string // Keys per-service settings, such as RateLimits and SecurityHeaders.
+	RoutePrefix() string
+}
+
+// A ServiceHealthChecker is a Service whose health is shown on the Console overview.
+// It is checked periodically while the server runs, not when the overview is loaded.
+type ServiceHealthChecker interface {
+	CheckHealth(ctx context.Context) error // Returns nil if the service is healthy.
+}
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+var errForPackage = errors.New("httpserver")
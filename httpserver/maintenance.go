@@ -0,0 +1,165 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// MaintenanceStatus is whether maintenance mode is on, as reported and set by the admin API.
+type MaintenanceStatus struct {
+	IsEnabled bool   `json:"enabled"`
+	Message   string `json:"message,omitempty"` // Shown on Console pages and in 503 responses.
+}
+
+// The maintenance mode of a running server.  It starts as configured and is changed through the admin API.
+type maintenance struct {
+	defaultMessage string // Used when maintenance mode is turned on without a message.
+	disablesXterm  bool
+	mutex          sync.RWMutex
+	retryAfter     int
+	status         MaintenanceStatus
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	defaultMaintenanceMessage    = "Senzing is down for maintenance.  Searches are still available."
+	defaultMaintenanceRetryAfter = 300
+)
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func newMaintenance(httpServer *BasicHTTPServer) *maintenance {
+	result := &maintenance{
+		defaultMessage: httpServer.MaintenanceMessage,
+		disablesXterm:  httpServer.MaintenanceDisablesXterm,
+		mutex:          sync.RWMutex{},
+		retryAfter:     httpServer.MaintenanceRetryAfter,
+		status:         MaintenanceStatus{IsEnabled: false, Message: ""},
+	}
+
+	if len(result.defaultMessage) == 0 {
+		result.defaultMessage = defaultMaintenanceMessage
+	}
+
+	if result.retryAfter <= 0 {
+		result.retryAfter = defaultMaintenanceRetryAfter
+	}
+
+	result.set(MaintenanceStatus{IsEnabled: httpServer.Maintenance, Message: ""})
+
+	return result
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// The message for the Console banner.  Empty unless maintenance mode is on.
+func (maintenance *maintenance) banner() string {
+	if maintenance == nil {
+		return ""
+	}
+
+	status := maintenance.get()
+	if !status.IsEnabled {
+		return ""
+	}
+
+	return status.Message
+}
+
+func (maintenance *maintenance) get() MaintenanceStatus {
+	maintenance.mutex.RLock()
+	defer maintenance.mutex.RUnlock()
+
+	return maintenance.status
+}
+
+/*
+Wrap a handler so that, in maintenance mode, writes to the Senzing REST APIs are refused with a 503.
+Searches and reads are still served.  If MaintenanceDisablesXterm, so is every XTerm request.
+*/
+func (maintenance *maintenance) handler(serviceName string, next http.Handler) http.Handler {
+	if maintenance == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if maintenance.isRefused(serviceName, request) {
+			writer.Header().Set("Retry-After", strconv.Itoa(maintenance.retryAfter))
+			writeProblem(writer, request, http.StatusServiceUnavailable, maintenance.get().Message)
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (maintenance *maintenance) isRefused(serviceName string, request *http.Request) bool {
+	if !maintenance.get().IsEnabled {
+		return false
+	}
+
+	switch {
+	case isAPIServiceName(serviceName):
+		return apiRouteClass(request) == RouteClassWrite
+	case serviceName == ServiceNameXterm:
+		return maintenance.disablesXterm
+	default:
+		return false
+	}
+}
+
+// Report whether maintenance mode keeps a service from being used, for the Console overview.
+func (maintenance *maintenance) isServiceDisabled(serviceName string) bool {
+	if maintenance == nil || serviceName != ServiceNameXterm || !maintenance.disablesXterm {
+		return false
+	}
+
+	return maintenance.get().IsEnabled
+}
+
+func (maintenance *maintenance) set(status MaintenanceStatus) {
+	if status.IsEnabled && len(status.Message) == 0 {
+		status.Message = maintenance.defaultMessage
+	}
+
+	if !status.IsEnabled {
+		status.Message = ""
+	}
+
+	maintenance.mutex.Lock()
+	defer maintenance.mutex.Unlock()
+
+	maintenance.status = status
+}
+
+// GET reports the maintenance mode.  PUT sets it from a MaintenanceStatus and reports the result.
+func (maintenance *maintenance) statusFunc(writer http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPut {
+		var status MaintenanceStatus
+
+		err := json.NewDecoder(request.Body).Decode(&status)
+		if err != nil {
+			writeProblem(writer, request, http.StatusBadRequest, "body is not a maintenance status: "+err.Error())
+
+			return
+		}
+
+		maintenance.set(status)
+	}
+
+	writeJSON(writer, maintenance.get())
+}
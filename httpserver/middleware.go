@@ -0,0 +1,28 @@
+package httpserver
+
+import (
+	"net/http"
+	"slices"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Middleware wraps a handler, for example to authenticate, log, or resolve a tenant before calling it.
+type Middleware func(next http.Handler) http.Handler
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Wrap a handler in middlewares.  The first middleware is outermost, so it sees each request first.
+func chainMiddlewares(handler http.Handler, middlewares []Middleware) http.Handler {
+	for _, middleware := range slices.Backward(middlewares) {
+		if middleware != nil {
+			handler = middleware(handler)
+		}
+	}
+
+	return handler
+}
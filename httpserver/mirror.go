@@ -0,0 +1,332 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Copies a sample of read-only Senzing REST API requests to a shadow backend and reports how it responds.
+type mirror struct {
+	client    *http.Client
+	logger    *slog.Logger
+	mutex     sync.Mutex // Guards the report.
+	percent   int
+	report    *json.Encoder
+	shadowURL *url.URL
+	slots     chan struct{} // Bounds the requests in flight to the shadow backend.
+}
+
+// A line of the mirroring report.
+type mirrorRecord struct {
+	Differences      []string  `json:"differences,omitempty"` // "status", "body", or top-level JSON members.
+	Error            string    `json:"error,omitempty"`
+	Method           string    `json:"method"`
+	Path             string    `json:"path"`
+	PrimaryLatencyMs float64   `json:"primaryLatencyMs"`
+	PrimaryStatus    int       `json:"primaryStatus"`
+	RequestID        string    `json:"requestId"`
+	ShadowLatencyMs  float64   `json:"shadowLatencyMs"`
+	ShadowStatus     int       `json:"shadowStatus"`
+	Time             time.Time `json:"time"`
+}
+
+// Keeps a copy of the primary response, so that the shadow response can be compared with it.
+type mirrorResponseWriter struct {
+	http.ResponseWriter
+
+	body        bytes.Buffer
+	isTruncated bool
+	statusCode  int
+	wroteHeader bool
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	mirrorMaxBodyBytes    = 1 << 20
+	mirrorMaxInFlight     = 16
+	mirrorReportFileMode  = 0o600
+	mirrorShadowTimeout   = 30 * time.Second
+	millisecondsPerSecond = 1000
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// Request headers that carry the client's credentials, which are not the shadow backend's to see.
+var mirrorCredentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// Top-level members of Senzing REST API responses that differ on every request, like timings and links.
+var mirrorIgnoredMembers = map[string]bool{
+	"links": true,
+	"meta":  true,
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Compare two responses.  JSON objects are compared member by member, so that key order does not matter.
+func mirrorDifferences(primaryBody []byte, shadowBody []byte) []string {
+	var primaryMembers, shadowMembers map[string]json.RawMessage
+
+	if json.Unmarshal(primaryBody, &primaryMembers) != nil || json.Unmarshal(shadowBody, &shadowMembers) != nil {
+		if bytes.Equal(primaryBody, shadowBody) {
+			return nil
+		}
+
+		return []string{"body"}
+	}
+
+	var result []string
+
+	names := slices.Collect(maps.Keys(primaryMembers))
+	for name := range shadowMembers {
+		if _, found := primaryMembers[name]; !found {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if !mirrorIgnoredMembers[name] && !jsonEqual(primaryMembers[name], shadowMembers[name]) {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+func jsonEqual(first json.RawMessage, second json.RawMessage) bool {
+	var firstValue, secondValue any
+
+	if json.Unmarshal(first, &firstValue) != nil || json.Unmarshal(second, &secondValue) != nil {
+		return bytes.Equal(first, second)
+	}
+
+	return reflect.DeepEqual(firstValue, secondValue)
+}
+
+func newMirror(ctx context.Context, httpServer *BasicHTTPServer) (*mirror, error) {
+	shadowURL, err := parseUpstreamURL(httpServer.MirrorURL)
+	if err != nil {
+		return nil, err
+	}
+
+	reportFile, err := os.OpenFile(
+		httpServer.MirrorReportFile,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		mirrorReportFileMode,
+	)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "OpenFile %s", httpServer.MirrorReportFile)
+	}
+
+	context.AfterFunc(ctx, func() { _ = reportFile.Close() })
+
+	return &mirror{
+		client:    &http.Client{Timeout: mirrorShadowTimeout}, //nolint:exhaustruct
+		logger:    httpServer.logger,
+		mutex:     sync.Mutex{},
+		percent:   httpServer.MirrorPercent,
+		report:    json.NewEncoder(reportFile),
+		shadowURL: shadowURL,
+		slots:     make(chan struct{}, mirrorMaxInFlight),
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+/*
+Wrap a handler so that a sample of its GET and HEAD requests are replayed against the shadow backend
+after the primary has responded.  Replays never delay the primary: if too many are in flight, the sample is skipped.
+*/
+func (mirror *mirror) handler(next http.Handler) http.Handler {
+	if mirror == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		isReadOnly := request.Method == http.MethodGet || request.Method == http.MethodHead
+		if !isReadOnly || rand.IntN(100) >= mirror.percent { //nolint:gosec,mnd
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		mirrorWriter := &mirrorResponseWriter{
+			ResponseWriter: writer,
+			body:           bytes.Buffer{},
+			isTruncated:    false,
+			statusCode:     http.StatusOK,
+			wroteHeader:    false,
+		}
+		start := time.Now()
+		next.ServeHTTP(mirrorWriter, request)
+
+		record := mirrorRecord{
+			Differences:      nil,
+			Error:            "",
+			Method:           request.Method,
+			Path:             request.RequestURI,
+			PrimaryLatencyMs: float64(time.Since(start).Microseconds()) / millisecondsPerSecond,
+			PrimaryStatus:    mirrorWriter.statusCode,
+			RequestID:        RequestID(request.Context()),
+			ShadowLatencyMs:  0,
+			ShadowStatus:     0,
+			Time:             start,
+		}
+
+		shadowRequest, err := mirror.newShadowRequest(request)
+		if err != nil {
+			record.Error = err.Error()
+			mirror.writeRecord(request.Context(), record)
+
+			return
+		}
+
+		select {
+		case mirror.slots <- struct{}{}:
+			go func() {
+				defer func() { <-mirror.slots }()
+
+				mirror.replay(shadowRequest, mirrorWriter, record)
+			}()
+		default:
+		}
+	})
+}
+
+// A copy of the request for the shadow backend, without the client's credentials.  It outlives the request, so does
+// not share its cancellation.
+func (mirror *mirror) newShadowRequest(request *http.Request) (*http.Request, error) {
+	shadowURL := *mirror.shadowURL
+	shadowURL.Path = strings.TrimSuffix(shadowURL.Path, "/") + request.URL.Path
+	shadowURL.RawPath = ""
+	shadowURL.RawQuery = request.URL.RawQuery
+
+	result, err := http.NewRequestWithContext(
+		context.WithoutCancel(request.Context()),
+		request.Method,
+		shadowURL.String(),
+		nil,
+	)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "NewRequestWithContext")
+	}
+
+	result.Header = request.Header.Clone()
+	result.Header.Del("Accept-Encoding") // So that the client decompresses the response.
+
+	for _, header := range mirrorCredentialHeaders {
+		result.Header.Del(header)
+	}
+
+	result.Header.Set(requestIDHeader, RequestID(request.Context()))
+
+	return result, nil
+}
+
+func (mirror *mirror) replay(shadowRequest *http.Request, mirrorWriter *mirrorResponseWriter, record mirrorRecord) {
+	start := time.Now()
+
+	response, err := mirror.client.Do(shadowRequest)
+	if err != nil {
+		record.Error = err.Error()
+		mirror.writeRecord(shadowRequest.Context(), record)
+
+		return
+	}
+
+	shadowBody, err := io.ReadAll(io.LimitReader(response.Body, mirrorMaxBodyBytes+1))
+	_ = response.Body.Close()
+	record.ShadowLatencyMs = float64(time.Since(start).Microseconds()) / millisecondsPerSecond
+	record.ShadowStatus = response.StatusCode
+
+	if record.ShadowStatus != record.PrimaryStatus {
+		record.Differences = append(record.Differences, "status")
+	}
+
+	switch {
+	case err != nil:
+		record.Error = err.Error()
+	case mirrorWriter.isTruncated || len(shadowBody) > mirrorMaxBodyBytes:
+		record.Error = "response too large to compare"
+	default:
+		record.Differences = append(record.Differences, mirrorDifferences(mirrorWriter.body.Bytes(), shadowBody)...)
+	}
+
+	mirror.writeRecord(shadowRequest.Context(), record)
+}
+
+func (mirror *mirror) writeRecord(ctx context.Context, record mirrorRecord) {
+	mirror.mutex.Lock()
+	defer mirror.mutex.Unlock()
+
+	err := mirror.report.Encode(record)
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		mirror.logger.WarnContext(
+			ctx,
+			"mirroring report not written",
+			"requestId", record.RequestID,
+			"error", err.Error(),
+		)
+	}
+}
+
+func (writer *mirrorResponseWriter) Flush() {
+	_ = http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+func (writer *mirrorResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *mirrorResponseWriter) Write(data []byte) (int, error) {
+	if !writer.wroteHeader {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	if writer.body.Len()+len(data) <= mirrorMaxBodyBytes {
+		writer.body.Write(data)
+	} else {
+		writer.isTruncated = true
+	}
+
+	return writer.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+func (writer *mirrorResponseWriter) WriteHeader(statusCode int) {
+	if !writer.wroteHeader && statusCode >= http.StatusOK {
+		writer.wroteHeader = true
+		writer.statusCode = statusCode
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,436 @@
+package httpserver
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/senzing-garage/go-observing/observer"
+	"github.com/senzing-garage/go-rest-api-service/senzingrestapi"
+	"github.com/senzing-garage/go-rest-api-service/senzingrestservice"
+	"google.golang.org/grpc"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Option configures the BasicHTTPServer built by New.
+type Option func(httpServer *BasicHTTPServer)
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// Defaults applied by New.  They match the defaults of the senzing-tools command line options.
+const (
+	defaultLogLevelName              = "INFO"
+	defaultReadHeaderTimeout         = 60 * time.Second
+	defaultServerAddress             = "0.0.0.0"
+	defaultServerPort                = 8260
+	defaultXtermCommand              = "/bin/bash"
+	defaultXtermConnectionErrorLimit = 10
+	defaultXtermKeepalivePingTimeout = 20
+	defaultXtermMaxBufferSizeBytes   = 512
+)
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The New function builds a BasicHTTPServer from defaults and options, then validates it.
+
+Input
+  - options: Applied in order, so a later option overrides an earlier one.
+
+Output
+  - A BasicHTTPServer ready to Serve.
+  - An error joining every problem found by Validate.
+*/
+func New(options ...Option) (*BasicHTTPServer, error) {
+	result := &BasicHTTPServer{
+		APIUrlRoutePrefix:         ServiceNameAPI,
+		GrpcWebURLRoutePrefix:     ServiceNameGrpcWeb,
+		LogLevelName:              defaultLogLevelName,
+		OpenAPISpecificationRest:  senzingrestservice.OpenAPISpecificationJSON,
+		ReadHeaderTimeout:         defaultReadHeaderTimeout,
+		ServerAddress:             defaultServerAddress,
+		ServerPort:                defaultServerPort,
+		SwaggerURLRoutePrefix:     ServiceNameSwagger,
+		XtermAllowedHostnames:     []string{"localhost"},
+		XtermCommand:              defaultXtermCommand,
+		XtermConnectionErrorLimit: defaultXtermConnectionErrorLimit,
+		XtermKeepalivePingTimeout: defaultXtermKeepalivePingTimeout,
+		XtermMaxBufferSizeBytes:   defaultXtermMaxBufferSizeBytes,
+		XtermURLRoutePrefix:       ServiceNameXterm,
+	}
+
+	for _, option := range options {
+		option(result)
+	}
+
+	err := result.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// WithAddress sets the interface and port to listen on.
+func WithAddress(serverAddress string, serverPort int) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.ServerAddress = serverAddress
+		httpServer.ServerPort = serverPort
+	}
+}
+
+// WithAdminToken serves the admin API at "/admin/" to requests bearing adminToken.
+func WithAdminToken(adminToken string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.AdminToken = adminToken
+	}
+}
+
+// WithAllowedCIDRs sets the clients that are served, as returned by ParseCIDRs.
+func WithAllowedCIDRs(allowedCIDRs []netip.Prefix, serviceAllowedCIDRs map[string][]netip.Prefix) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.AllowedCIDRs = allowedCIDRs
+		httpServer.ServiceAllowedCIDRs = serviceAllowedCIDRs
+	}
+}
+
+// WithAPIInstances mounts additional Senzing REST APIs, each for its own Senzing repository, at "/api/{Name}/".
+func WithAPIInstances(apiInstances ...APIInstance) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.APIInstances = apiInstances
+	}
+}
+
+// WithAvoidServing builds everything, but does not listen.  Used in testing.
+func WithAvoidServing(avoidServing bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.AvoidServing = avoidServing
+	}
+}
+
+// WithCompression enables response compression.  See CompressionMinSize and CompressionContentTypes.
+func WithCompression(enableCompression bool, minSize int, contentTypes ...string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableCompression = enableCompression
+		httpServer.CompressionMinSize = minSize
+		httpServer.CompressionContentTypes = contentTypes
+	}
+}
+
+// WithCORS sets the cross-origin policy of the Senzing REST API.
+func WithCORS(
+	allowedOrigins []string,
+	allowedMethods []string,
+	allowedHeaders []string,
+	allowCredentials bool,
+	maxAge int,
+) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.CORSAllowedOrigins = allowedOrigins
+		httpServer.CORSAllowedMethods = allowedMethods
+		httpServer.CORSAllowedHeaders = allowedHeaders
+		httpServer.CORSAllowCredentials = allowCredentials
+		httpServer.CORSMaxAge = maxAge
+	}
+}
+
+// WithDeniedCIDRs sets the clients that are refused, as returned by ParseCIDRs.
+func WithDeniedCIDRs(deniedCIDRs []netip.Prefix, serviceDeniedCIDRs map[string][]netip.Prefix) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.DeniedCIDRs = deniedCIDRs
+		httpServer.ServiceDeniedCIDRs = serviceDeniedCIDRs
+	}
+}
+
+// WithEnableAll enables every built-in service.
+func WithEnableAll(enableAll bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableAll = enableAll
+	}
+}
+
+// WithEnableGrpcWeb enables the gRPC-Web gateway to GrpcTarget.
+func WithEnableGrpcWeb(enableGrpcWeb bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableGrpcWeb = enableGrpcWeb
+	}
+}
+
+// WithEnableH2C serves HTTP/2 without TLS, for clients such as service mesh proxies that terminate TLS.
+func WithEnableH2C(enableH2C bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableH2C = enableH2C
+	}
+}
+
+// WithEnableSenzingRestAPI enables the Senzing REST API.
+func WithEnableSenzingRestAPI(enableSenzingRestAPI bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableSenzingRestAPI = enableSenzingRestAPI
+	}
+}
+
+// WithEnableSwaggerUI enables the Swagger UI.
+func WithEnableSwaggerUI(enableSwaggerUI bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableSwaggerUI = enableSwaggerUI
+	}
+}
+
+// WithEnableXterm enables the web terminal.
+func WithEnableXterm(enableXterm bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.EnableXterm = enableXterm
+	}
+}
+
+// WithGrpc has the Senzing REST API use a Senzing gRPC server instead of a local Senzing engine.
+func WithGrpc(grpcTarget string, grpcDialOptions ...grpc.DialOption) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.GrpcTarget = grpcTarget
+		httpServer.GrpcDialOptions = grpcDialOptions
+	}
+}
+
+// WithGrpcTargets balances the Senzing REST API and gRPC-Web gateway across several Senzing gRPC servers,
+// dialed with the options of WithGrpc.  Failing servers are ejected until their health checks pass.
+func WithGrpcTargets(grpcLoadBalancing string, grpcTargets ...string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.GrpcLoadBalancing = grpcLoadBalancing
+		httpServer.GrpcTargets = grpcTargets
+	}
+}
+
+// WithHSTSMaxAge sets the Strict-Transport-Security max-age, in seconds, of responses over TLS.
+func WithHSTSMaxAge(hstsMaxAge int) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.HSTSMaxAge = hstsMaxAge
+	}
+}
+
+// WithLogLevelName sets the log level: TRACE, DEBUG, INFO, WARN, ERROR, FATAL, or PANIC.
+func WithLogLevelName(logLevelName string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.LogLevelName = logLevelName
+	}
+}
+
+// WithLogLevelRevertAfter sets how long log level changes made at runtime last before LogLevelName is restored.
+func WithLogLevelRevertAfter(logLevelRevertAfter time.Duration) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.LogLevelRevertAfter = logLevelRevertAfter
+	}
+}
+
+/*
+WithMaintenance starts in maintenance mode if isEnabled, refusing Senzing REST API writes with a 503
+that asks clients to retry after retryAfter seconds.  If disablesXterm, XTerm is refused too.
+Maintenance mode can be changed at runtime through the admin API.
+*/
+func WithMaintenance(isEnabled bool, message string, retryAfter int, disablesXterm bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.Maintenance = isEnabled
+		httpServer.MaintenanceDisablesXterm = disablesXterm
+		httpServer.MaintenanceMessage = message
+		httpServer.MaintenanceRetryAfter = retryAfter
+	}
+}
+
+// WithMaxHeaderBytes limits the size of request headers.
+func WithMaxHeaderBytes(maxHeaderBytes int) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.MaxHeaderBytes = maxHeaderBytes
+	}
+}
+
+// WithMaxRequestBodyBytes overrides the default request body limits, as returned by ParseMaxRequestBodyBytes.
+func WithMaxRequestBodyBytes(maxRequestBodyBytes map[string]int64) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.MaxRequestBodyBytes = maxRequestBodyBytes
+	}
+}
+
+// WithMiddlewares adds middlewares that wrap every request.
+func WithMiddlewares(middlewares ...Middleware) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.Middlewares = append(httpServer.Middlewares, middlewares...)
+	}
+}
+
+// WithMirror copies percent of the read-only Senzing REST API requests to the shadow server at mirrorURL,
+// recording how its responses differ from the primary's in reportFile.
+func WithMirror(mirrorURL string, percent int, reportFile string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.MirrorPercent = percent
+		httpServer.MirrorReportFile = reportFile
+		httpServer.MirrorURL = mirrorURL
+	}
+}
+
+// WithObservers sets the observers notified by the Senzing REST API.
+func WithObservers(observerOrigin string, observers ...observer.Observer) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.ObserverOrigin = observerOrigin
+		httpServer.Observers = observers
+	}
+}
+
+// WithOpenAPISpecification replaces the OpenAPI specification served to the Swagger UI.
+func WithOpenAPISpecification(openAPISpecification []byte) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.OpenAPISpecificationRest = openAPISpecification
+	}
+}
+
+// WithRateLimits sets per-client rate limits, as returned by ParseRateLimits.
+func WithRateLimits(rateLimits map[string]RateLimit, rateLimitKeyHeader string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.RateLimits = rateLimits
+		httpServer.RateLimitKeyHeader = rateLimitKeyHeader
+	}
+}
+
+// WithReadHeaderTimeout limits the time allowed to read request headers.
+func WithReadHeaderTimeout(readHeaderTimeout time.Duration) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.ReadHeaderTimeout = readHeaderTimeout
+	}
+}
+
+// WithReverseProxies adds services that forward route prefixes to upstream HTTP servers.
+func WithReverseProxies(reverseProxies ...ReverseProxy) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.ReverseProxies = append(httpServer.ReverseProxies, reverseProxies...)
+	}
+}
+
+// WithRoutePrefixes sets the route prefixes of the Senzing REST API, Swagger UI, and web terminal.
+func WithRoutePrefixes(apiURLRoutePrefix string, swaggerURLRoutePrefix string, xtermURLRoutePrefix string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.APIUrlRoutePrefix = apiURLRoutePrefix
+		httpServer.SwaggerURLRoutePrefix = swaggerURLRoutePrefix
+		httpServer.XtermURLRoutePrefix = xtermURLRoutePrefix
+	}
+}
+
+// WithSecurityHeaders overrides the default security headers of services.
+func WithSecurityHeaders(securityHeaders map[string]SecurityHeaders) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.SecurityHeaders = securityHeaders
+	}
+}
+
+// WithSenzing sets the Senzing engine used by the Senzing REST API and the web terminal.
+func WithSenzing(senzingSettings string, senzingInstanceName string, senzingVerboseLogging int64) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.SenzingSettings = senzingSettings
+		httpServer.SenzingInstanceName = senzingInstanceName
+		httpServer.SenzingVerboseLogging = senzingVerboseLogging
+	}
+}
+
+// WithServerOptions sets options passed to senzingrestapi.NewServer.
+func WithServerOptions(serverOptions ...senzingrestapi.ServerOption) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.ServerOptions = serverOptions
+	}
+}
+
+// WithService registers a service.  See RegisterService.
+func WithService(service Service) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.RegisterService(service)
+	}
+}
+
+// WithServiceMiddlewares adds middlewares that wrap the requests of one service.
+func WithServiceMiddlewares(serviceName string, middlewares ...Middleware) Option {
+	return func(httpServer *BasicHTTPServer) {
+		if httpServer.ServiceMiddlewares == nil {
+			httpServer.ServiceMiddlewares = map[string][]Middleware{}
+		}
+
+		httpServer.ServiceMiddlewares[serviceName] = append(httpServer.ServiceMiddlewares[serviceName], middlewares...)
+	}
+}
+
+// WithStaticCacheMaxAge sets the seconds browsers may reuse static assets without revalidating.
+func WithStaticCacheMaxAge(staticCacheMaxAge int) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.StaticCacheMaxAge = staticCacheMaxAge
+	}
+}
+
+/*
+WithStaticDirectory serves a directory from disk at "/", such as the build of a single-page application.
+
+Input
+  - directory: The directory to serve.  Its files take precedence over the embedded assets.
+  - isExclusive: If true, the embedded assets are not served at all.
+  - isSPAFallback: If true, unknown routes without a file extension get the directory's index.html.
+*/
+func WithStaticDirectory(directory string, isExclusive bool, isSPAFallback bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.StaticDirectory = directory
+		httpServer.StaticDirectoryExclusive = isExclusive
+		httpServer.StaticSPAFallback = isSPAFallback
+	}
+}
+
+// WithTemplatesDirectory adds the templates of a directory to the Console, rendered under /site/.
+func WithTemplatesDirectory(templatesDirectory string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.TemplatesDirectory = templatesDirectory
+	}
+}
+
+// WithTrustedProxies sets the proxies, as returned by ParseCIDRs, whose X-Forwarded-Proto header is believed.
+func WithTrustedProxies(trustedProxies []netip.Prefix) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.TrustedProxies = trustedProxies
+	}
+}
+
+// WithTtyOnly avoids opening a web browser at startup.
+func WithTtyOnly(ttyOnly bool) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.TtyOnly = ttyOnly
+	}
+}
+
+// WithVersion sets the version reported to Console pages.
+func WithVersion(version string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.Version = version
+	}
+}
+
+// WithXterm sets the command the web terminal runs.
+func WithXterm(xtermCommand string, xtermArguments ...string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.XtermCommand = xtermCommand
+		httpServer.XtermArguments = xtermArguments
+	}
+}
+
+// WithXtermAllowedHostnames sets the hostnames the web terminal accepts websocket connections from.
+func WithXtermAllowedHostnames(xtermAllowedHostnames ...string) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.XtermAllowedHostnames = xtermAllowedHostnames
+	}
+}
+
+// WithXtermLimits sets the connection limits of the web terminal.
+func WithXtermLimits(connectionErrorLimit int, keepalivePingTimeout int, maxBufferSizeBytes int) Option {
+	return func(httpServer *BasicHTTPServer) {
+		httpServer.XtermConnectionErrorLimit = connectionErrorLimit
+		httpServer.XtermKeepalivePingTimeout = keepalivePingTimeout
+		httpServer.XtermMaxBufferSizeBytes = maxBufferSizeBytes
+	}
+}
@@ -0,0 +1,329 @@
+package httpserver
+
+import (
+	"cmp"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// RateLimit is a token-bucket limit.  Tokens are added at RequestsPerSecond up to Burst.
+type RateLimit struct {
+	Burst             int     `json:"burst"`
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+}
+
+type rateLimitBucket struct {
+	key      rateLimitBucketKey
+	lastSeen time.Time
+	limit    RateLimit
+	tokens   float64
+}
+
+type rateLimitBucketKey struct {
+	client string
+	route  string
+}
+
+// Buckets kept in least recently used order, so that when there are too many, the oldest are evicted.
+type rateLimitBuckets struct {
+	elements map[rateLimitBucketKey]*list.Element // Of recent.
+	recent   *list.List                           // Of *rateLimitBucket, most recently used first.
+}
+
+/*
+Limits requests by client IP address, and by RateLimitKeyHeader if set.  The buckets of header values
+are kept apart from those of IP addresses, so that a client sending many header values can evict only
+header buckets, never the buckets that limit every client by IP address.
+*/
+type rateLimiter struct {
+	addressBuckets *rateLimitBuckets
+	headerBuckets  *rateLimitBuckets
+	keyHeader      string
+	limits         map[string]RateLimit
+	mutex          sync.Mutex
+	now            func() time.Time
+}
+
+type rateLimitClientStatus struct {
+	Client string  `json:"client"`
+	Route  string  `json:"route"`
+	Tokens float64 `json:"tokens"`
+}
+
+type rateLimitStatus struct {
+	Clients   []rateLimitClientStatus `json:"clients"`
+	KeyHeader string                  `json:"keyHeader,omitempty"`
+	Limits    map[string]RateLimit    `json:"limits"`
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	rateLimitMaxBuckets    = 10000
+	rateLimitFingerprintSz = 8
+)
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The ParseRateLimits function parses rate limits of the form "name=requestsPerSecond:burst".
+
+Input
+  - specifications: Values like "api=10:20" or "api/search=2:5".
+
+Output
+  - A map of rate limits keyed by name.
+*/
+func ParseRateLimits(specifications []string) (map[string]RateLimit, error) {
+	result := map[string]RateLimit{}
+
+	for _, specification := range specifications {
+		name, value, found := strings.Cut(specification, "=")
+		if !found || len(name) == 0 {
+			return nil, wraperror.Errorf(
+				errForPackage,
+				"rate limit %q is not of the form name=requestsPerSecond:burst",
+				specification,
+			)
+		}
+
+		requestsPerSecondText, burstText, found := strings.Cut(value, ":")
+		if !found {
+			return nil, wraperror.Errorf(errForPackage, "rate limit %q is missing a burst", specification)
+		}
+
+		requestsPerSecond, err := strconv.ParseFloat(requestsPerSecondText, 64)
+		if err != nil || requestsPerSecond <= 0 {
+			return nil, wraperror.Errorf(errForPackage, "rate limit %q has invalid requests per second", specification)
+		}
+
+		burst, err := strconv.Atoi(burstText)
+		if err != nil || burst < 1 {
+			return nil, wraperror.Errorf(errForPackage, "rate limit %q has an invalid burst", specification)
+		}
+
+		result[strings.TrimSpace(name)] = RateLimit{
+			Burst:             burst,
+			RequestsPerSecond: requestsPerSecond,
+		}
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func newRateLimitBuckets() *rateLimitBuckets {
+	return &rateLimitBuckets{
+		elements: map[rateLimitBucketKey]*list.Element{},
+		recent:   list.New(),
+	}
+}
+
+func newRateLimiter(limits map[string]RateLimit, keyHeader string) *rateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		addressBuckets: newRateLimitBuckets(),
+		headerBuckets:  newRateLimitBuckets(),
+		keyHeader:      keyHeader,
+		limits:         limits,
+		mutex:          sync.Mutex{},
+		now:            time.Now,
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that requests for a service are rate limited.
+func (limiter *rateLimiter) handler(serviceName string, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		allowed, retryAfter := limiter.allow(routeName(serviceName, request), limiter.clientKeys(request)...)
+		if !allowed {
+			writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeProblem(writer, request, http.StatusTooManyRequests, "rate limit exceeded")
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Take a token from the bucket of each of the client's keys.
+// If any bucket is empty, none are taken, and how long until one is available is reported.
+func (limiter *rateLimiter) allow(route string, clients ...string) (bool, time.Duration) {
+	bucketRoute, limit, found := lookupRoute(limiter.limits, route)
+	if !found {
+		return true, 0
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	clientBuckets := make([]*rateLimitBucket, 0, len(clients))
+
+	var retryAfter time.Duration
+
+	for _, client := range clients {
+		buckets := limiter.addressBuckets
+		if limiter.isHeaderClient(client) {
+			buckets = limiter.headerBuckets
+		}
+
+		bucket := buckets.bucket(rateLimitBucketKey{client: client, route: bucketRoute}, limit, now)
+		if bucket.tokens < 1 {
+			missing := 1 - bucket.tokens
+			retryAfter = max(retryAfter, time.Duration(missing/limit.RequestsPerSecond*float64(time.Second)))
+		}
+
+		clientBuckets = append(clientBuckets, bucket)
+	}
+
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for _, bucket := range clientBuckets {
+		bucket.tokens--
+	}
+
+	return true, 0
+}
+
+// The refilled bucket of a key, marked as the most recently used.  Callers hold the mutex of the rateLimiter.
+// A new bucket evicts the least recently used one if there are rateLimitMaxBuckets already.
+func (buckets *rateLimitBuckets) bucket(key rateLimitBucketKey, limit RateLimit, now time.Time) *rateLimitBucket {
+	element, found := buckets.elements[key]
+	if found {
+		buckets.recent.MoveToFront(element)
+
+		bucket, _ := element.Value.(*rateLimitBucket)
+		bucket.refill(now)
+
+		return bucket
+	}
+
+	if buckets.recent.Len() >= rateLimitMaxBuckets {
+		oldest := buckets.recent.Back()
+		oldestBucket, _ := oldest.Value.(*rateLimitBucket)
+		delete(buckets.elements, oldestBucket.key)
+		buckets.recent.Remove(oldest)
+	}
+
+	bucket := &rateLimitBucket{
+		key:      key,
+		lastSeen: now,
+		limit:    limit,
+		tokens:   float64(limit.Burst),
+	}
+	buckets.elements[key] = buckets.recent.PushFront(bucket)
+
+	return bucket
+}
+
+/*
+The keys of the buckets a request takes tokens from.  Requests are always limited by client IP address.
+If RateLimitKeyHeader is set, requests bearing it are limited by its value too.  The header value alone
+would let a client bypass its limit by sending a new value with every request.
+*/
+func (limiter *rateLimiter) clientKeys(request *http.Request) []string {
+	result := []string{clientIP(request)}
+
+	if len(limiter.keyHeader) > 0 {
+		value := request.Header.Get(limiter.keyHeader)
+		if len(value) > 0 {
+			result = append(result, limiter.keyHeader+":"+value)
+		}
+	}
+
+	return result
+}
+
+// Report whether a client key is a RateLimitKeyHeader value, rather than an IP address.
+func (limiter *rateLimiter) isHeaderClient(client string) bool {
+	return len(limiter.keyHeader) > 0 && strings.HasPrefix(client, limiter.keyHeader+":")
+}
+
+func (limiter *rateLimiter) status() rateLimitStatus {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	result := rateLimitStatus{
+		Clients:   []rateLimitClientStatus{},
+		KeyHeader: limiter.keyHeader,
+		Limits:    limiter.limits,
+	}
+
+	for _, buckets := range []*rateLimitBuckets{limiter.addressBuckets, limiter.headerBuckets} {
+		for element := buckets.recent.Front(); element != nil; element = element.Next() {
+			bucket, _ := element.Value.(*rateLimitBucket)
+			bucket.refill(now)
+			result.Clients = append(result.Clients, rateLimitClientStatus{
+				Client: limiter.redact(bucket.key.client),
+				Route:  bucket.key.route,
+				Tokens: bucket.tokens,
+			})
+		}
+	}
+
+	slices.SortFunc(result.Clients, func(a, b rateLimitClientStatus) int {
+		return cmp.Or(cmp.Compare(a.Route, b.Route), cmp.Compare(a.Client, b.Client))
+	})
+
+	return result
+}
+
+// Header values may be API keys, so only a fingerprint is reported.
+func (limiter *rateLimiter) redact(client string) string {
+	if !limiter.isHeaderClient(client) {
+		return client
+	}
+
+	sum := sha256.Sum256([]byte(client))
+
+	return limiter.keyHeader + ":" + hex.EncodeToString(sum[:rateLimitFingerprintSz])
+}
+
+// GET reports the limits and the buckets of the clients, for the admin API.
+func (limiter *rateLimiter) statusFunc(writer http.ResponseWriter, request *http.Request) {
+	_ = request
+
+	writeJSON(writer, limiter.status())
+}
+
+func (bucket *rateLimitBucket) refill(now time.Time) {
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.lastSeen = now
+	bucket.tokens = math.Min(float64(bucket.limit.Burst), bucket.tokens+elapsed*bucket.limit.RequestsPerSecond)
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+type requestIDKey struct{}
+
+// Records whether the response has started, so a recovered panic knows if it can still respond.
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	requestIDBytes     = 16
+	requestIDHeader    = "X-Request-Id"
+	requestIDMaxLength = 128
+)
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The RequestID function returns the ID of the request being served.
+The ID is taken from the request's X-Request-Id header, if it has a usable one, and is echoed in the response.
+
+Input
+  - ctx: The context of the request.
+
+Output
+  - The request ID, or "" outside of a request.
+*/
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// An incoming request ID is only trusted if it is short and printable, as it is logged and echoed.
+func isUsableRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > requestIDMaxLength {
+		return false
+	}
+
+	for _, character := range []byte(requestID) {
+		if character < '!' || character > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	requestID := make([]byte, requestIDBytes)
+	_, _ = rand.Read(requestID)
+
+	return hex.EncodeToString(requestID)
+}
+
+// Wrap a handler so that every request has an ID in its context and its response.
+func requestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestID := request.Header.Get(requestIDHeader)
+		if !isUsableRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		writer.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(writer, request.WithContext(context.WithValue(request.Context(), requestIDKey{}, requestID)))
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that a panic is logged with its stack and answered with a 500, rather than dropping the connection.
+func (httpServer *BasicHTTPServer) recoveryHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		recoveryWriter := &recoveryResponseWriter{
+			ResponseWriter: writer,
+			wroteHeader:    false,
+		}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// net/http uses this panic to abort a response on purpose.
+			if recovered == http.ErrAbortHandler { //nolint:errorlint
+				panic(recovered)
+			}
+
+			httpServer.logger.ErrorContext(
+				request.Context(),
+				"panic serving request",
+				"requestId", RequestID(request.Context()),
+				"method", request.Method,
+				"path", request.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+
+			if !recoveryWriter.wroteHeader {
+				writeProblem(writer, request, http.StatusInternalServerError, "")
+			}
+		}()
+
+		next.ServeHTTP(recoveryWriter, request)
+	})
+}
+
+// Log an error and respond with a 500 that does not reveal it.
+func (httpServer *BasicHTTPServer) writeInternalServerError(
+	writer http.ResponseWriter,
+	request *http.Request,
+	err error,
+) {
+	httpServer.logger.ErrorContext(
+		request.Context(),
+		"error serving request",
+		"requestId", RequestID(request.Context()),
+		"method", request.Method,
+		"path", request.URL.Path,
+		"error", err.Error(),
+	)
+	writeProblem(writer, request, http.StatusInternalServerError, "")
+}
+
+func (writer *recoveryResponseWriter) Flush() {
+	writer.wroteHeader = true
+	_ = http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+// Websocket upgrades, like those of the web terminal, need the connection.
+func (writer *recoveryResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	writer.wroteHeader = true
+	connection, readWriter, err := http.NewResponseController(writer.ResponseWriter).Hijack()
+
+	return connection, readWriter, wraperror.Errorf(err, "Hijack")
+}
+
+func (writer *recoveryResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *recoveryResponseWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+
+	return writer.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+func (writer *recoveryResponseWriter) WriteHeader(statusCode int) {
+	if statusCode >= http.StatusOK {
+		writer.wroteHeader = true
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,230 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// ReverseProxy describes a service that forwards the requests of a route prefix to an upstream HTTP server.
+// Websocket upgrades are passed through.
+type ReverseProxy struct {
+	HealthPath      string            // Requested for the Console overview.  If empty, the UpstreamURL is.
+	Name            string            // Keys per-service settings, such as RateLimits and SecurityHeaders.
+	RequestHeaders  map[string]string // Set on forwarded requests.  An empty value removes the header.
+	ResponseHeaders map[string]string // Set on upstream responses.  An empty value removes the header.
+	RoutePrefix     string            // If empty, the Name.
+	Timeout         time.Duration     // For the upstream's response headers.  If 0, 60 seconds.
+	UpstreamURL     string            // e.g. "http://legacy.example.com:8250/api"
+}
+
+// The Service implementation of a ReverseProxy.
+type reverseProxyService struct {
+	httpServer   *BasicHTTPServer
+	reverseProxy ReverseProxy
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	reverseProxyDefaultTimeout = 60 * time.Second
+	reverseProxyHealthTimeout  = 2 * time.Second
+)
+
+// ----------------------------------------------------------------------------
+// Public functions
+// ----------------------------------------------------------------------------
+
+/*
+The ParseReverseProxies function parses reverse proxies of the form "name=upstreamURL".
+The name is also the route prefix.
+
+Input
+  - specifications: Values like "legacy=http://legacy.example.com:8250".
+
+Output
+  - The reverse proxies, in the order given.
+*/
+func ParseReverseProxies(specifications []string) ([]ReverseProxy, error) {
+	result := make([]ReverseProxy, 0, len(specifications))
+
+	for _, specification := range specifications {
+		name, upstreamURL, found := strings.Cut(specification, "=")
+		name = strings.TrimSpace(name)
+
+		if !found || len(name) == 0 {
+			return nil, wraperror.Errorf(
+				errForPackage,
+				"reverse proxy %s is not of the form name=upstreamURL",
+				specification,
+			)
+		}
+
+		_, err := parseUpstreamURL(upstreamURL)
+		if err != nil {
+			return nil, wraperror.Errorf(err, "reverse proxy %s", name)
+		}
+
+		result = append(result, ReverseProxy{
+			HealthPath:      "",
+			Name:            name,
+			RequestHeaders:  nil,
+			ResponseHeaders: nil,
+			RoutePrefix:     "",
+			Timeout:         0,
+			UpstreamURL:     strings.TrimSpace(upstreamURL),
+		})
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func parseUpstreamURL(upstreamURL string) (*url.URL, error) {
+	result, err := url.Parse(strings.TrimSpace(upstreamURL))
+	if err != nil || (result.Scheme != "http" && result.Scheme != "https") || len(result.Host) == 0 {
+		return nil, wraperror.Errorf(errForPackage, "upstream URL %s is not an absolute http or https URL", upstreamURL)
+	}
+
+	return result, nil
+}
+
+// Set headers, removing those with an empty value.
+func rewriteHeaders(header http.Header, values map[string]string) {
+	for name, value := range values {
+		if len(value) == 0 {
+			header.Del(name)
+		} else {
+			header.Set(name, value)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Public methods
+// ----------------------------------------------------------------------------
+
+func (service *reverseProxyService) Banner(serviceURL string) string {
+	return fmt.Sprintf("Serving %-16s at %s from %s", service.Name(), serviceURL, service.reverseProxy.UpstreamURL)
+}
+
+// Check that the upstream responds without a server error.
+func (service *reverseProxyService) CheckHealth(ctx context.Context) error {
+	healthURL := strings.TrimSuffix(service.reverseProxy.UpstreamURL, "/") + service.reverseProxy.HealthPath
+
+	ctx, cancel := context.WithTimeout(ctx, reverseProxyHealthTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return wraperror.Errorf(err, "NewRequestWithContext")
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return wraperror.Errorf(err, "Do %s", healthURL)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return wraperror.Errorf(errForPackage, "upstream %s responded %s", healthURL, response.Status)
+	}
+
+	return nil
+}
+
+func (service *reverseProxyService) ConsoleCard() ConsoleCard {
+	return ConsoleCard{
+		CommandLineOption:   "--reverse-proxies",
+		EnvironmentVariable: "SENZING_TOOLS_REVERSE_PROXIES",
+		Title:               service.Name() + " (" + service.reverseProxy.UpstreamURL + ")",
+	}
+}
+
+func (service *reverseProxyService) Handler(ctx context.Context) (http.Handler, error) {
+	_ = ctx
+
+	upstream, err := parseUpstreamURL(service.reverseProxy.UpstreamURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultTransport, isTransport := http.DefaultTransport.(*http.Transport)
+	if !isTransport {
+		return nil, wraperror.Errorf(errForPackage, "http.DefaultTransport is not an *http.Transport")
+	}
+
+	transport := defaultTransport.Clone()
+	transport.ResponseHeaderTimeout = service.reverseProxy.Timeout
+
+	if transport.ResponseHeaderTimeout == 0 {
+		transport.ResponseHeaderTimeout = reverseProxyDefaultTimeout
+	}
+
+	routePrefix := "/" + service.RoutePrefix()
+
+	return &httputil.ReverseProxy{
+		BufferPool: nil,
+		Director:   nil,
+		ErrorHandler: func(writer http.ResponseWriter, request *http.Request, err error) {
+			if !errors.Is(err, context.Canceled) {
+				service.httpServer.logger.WarnContext(
+					request.Context(),
+					"reverse proxy failed",
+					"requestId", RequestID(request.Context()),
+					"service", service.Name(),
+					"error", err.Error(),
+				)
+			}
+
+			writeProblem(writer, request, http.StatusBadGateway, "upstream of "+service.Name()+" is unavailable")
+		},
+		ErrorLog:      nil,
+		FlushInterval: 0,
+		ModifyResponse: func(response *http.Response) error {
+			rewriteHeaders(response.Header, service.reverseProxy.ResponseHeaders)
+
+			return nil
+		},
+		Rewrite: func(proxyRequest *httputil.ProxyRequest) {
+			proxyRequest.SetURL(upstream)
+			proxyRequest.SetXForwarded()
+			proxyRequest.Out.Header.Set("X-Forwarded-Prefix", routePrefix)
+			rewriteHeaders(proxyRequest.Out.Header, service.reverseProxy.RequestHeaders)
+		},
+		Transport: transport,
+	}, nil
+}
+
+func (service *reverseProxyService) IsEnabled() bool {
+	return true
+}
+
+func (service *reverseProxyService) Name() string {
+	return service.reverseProxy.Name
+}
+
+func (service *reverseProxyService) RoutePrefix() string {
+	if len(service.reverseProxy.RoutePrefix) > 0 {
+		return service.reverseProxy.RoutePrefix
+	}
+
+	return service.reverseProxy.Name
+}
@@ -0,0 +1,108 @@
+package httpserver
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// Route classes of the Senzing REST API.  Per-route settings are named "api/<class>",
+// and a setting named "api/search" takes precedence over one named "api".
+const (
+	RouteClassRead   = "read"
+	RouteClassSearch = "search"
+	RouteClassWrite  = "write"
+)
+
+// The path segment of the Senzing REST API's search endpoint.
+const apiSearchPathSegment = "search-entities"
+
+// Service names used to key per-service settings.
+const (
+	ServiceNameAdmin   = "admin"
+	ServiceNameAPI     = "api"
+	ServiceNameConsole = "console"
+	ServiceNameGrpcWeb = "grpc-web"
+	ServiceNameSwagger = "swagger"
+	ServiceNameXterm   = "xterm"
+)
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Classify a Senzing REST API request as a read, search, or write, by its method first.
+// Searches are GETs and POSTs of the search endpoint, so a write to a path that merely contains "search" is a write.
+func apiRouteClass(request *http.Request) string {
+	isRead := slices.Contains([]string{http.MethodGet, http.MethodHead, http.MethodOptions}, request.Method)
+	isSearch := (isRead || request.Method == http.MethodPost) &&
+		slices.Contains(strings.Split(request.URL.Path, "/"), apiSearchPathSegment)
+
+	switch {
+	case isSearch:
+		return RouteClassSearch
+	case isRead:
+		return RouteClassRead
+	default:
+		return RouteClassWrite
+	}
+}
+
+func clientIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
+
+// Find the most specific setting for a route.  "api/search" falls back to "api".
+// A route of an APIInstance, "api/sales/search", falls back to "api/sales", then "api/search", then "api".
+func lookupRoute[T any](settings map[string]T, route string) (string, T, bool) {
+	for _, name := range routeFallbacks(route) {
+		setting, found := settings[name]
+		if found {
+			return name, setting, true
+		}
+	}
+
+	var zero T
+
+	return "", zero, false
+}
+
+// Report whether a service is the Senzing REST API or one of its APIInstances, named "api/<instance>".
+func isAPIServiceName(serviceName string) bool {
+	return serviceName == ServiceNameAPI || strings.HasPrefix(serviceName, ServiceNameAPI+"/")
+}
+
+// The names a route's settings may be found under, most specific first.  See lookupRoute.
+func routeFallbacks(route string) []string {
+	segments := strings.Split(route, "/")
+	result := make([]string, 0, len(segments)+1)
+
+	for count := len(segments); count > 0; count-- {
+		if count == 1 && len(segments) == 3 && segments[0] == ServiceNameAPI {
+			result = append(result, ServiceNameAPI+"/"+segments[2])
+		}
+
+		result = append(result, strings.Join(segments[:count], "/"))
+	}
+
+	return result
+}
+
+// The name of the route a request is for: the service name, plus "/<class>" for the Senzing REST APIs.
+func routeName(serviceName string, request *http.Request) string {
+	if isAPIServiceName(serviceName) {
+		return serviceName + "/" + apiRouteClass(request)
+	}
+
+	return serviceName
+}
@@ -0,0 +1,177 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// SecurityHeaders are the security response headers applied to a service.
+// Empty fields fall back to the service's default.
+type SecurityHeaders struct {
+	ContentSecurityPolicy string // "{nonce}" is replaced by a per-request nonce.
+	FrameOptions          string
+	PermissionsPolicy     string
+	ReferrerPolicy        string
+}
+
+type securityHeadersNonceKey struct{}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const (
+	securityHeadersNonceBytes       = 16
+	securityHeadersNoncePlaceholder = "{nonce}"
+)
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// Swagger UI and xterm.js set inline styles from script, so only their scripts are locked down.
+// Both fetch from, and xterm.js opens its websocket to, the same origin.
+var defaultSecurityHeaders = map[string]SecurityHeaders{
+	ServiceNameAPI: {
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		FrameOptions:          "DENY",
+		PermissionsPolicy:     "camera=(), geolocation=(), microphone=()",
+		ReferrerPolicy:        "no-referrer",
+	},
+	ServiceNameConsole: {
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'; " +
+			"style-src 'self' 'nonce-{nonce}'; img-src 'self' data:; object-src 'none'; " +
+			"base-uri 'self'; form-action 'self'; frame-ancestors 'none'",
+		FrameOptions:      "DENY",
+		PermissionsPolicy: "camera=(), geolocation=(), microphone=()",
+		ReferrerPolicy:    "strict-origin-when-cross-origin",
+	},
+	ServiceNameSwagger: {
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+			"img-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'",
+		FrameOptions:      "DENY",
+		PermissionsPolicy: "camera=(), geolocation=(), microphone=()",
+		ReferrerPolicy:    "strict-origin-when-cross-origin",
+	},
+	ServiceNameXterm: {
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+			"img-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'",
+		FrameOptions:      "DENY",
+		PermissionsPolicy: "camera=(), geolocation=(), microphone=()",
+		ReferrerPolicy:    "no-referrer",
+	},
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func newSecurityHeadersNonce() string {
+	nonce := make([]byte, securityHeadersNonceBytes)
+	_, _ = rand.Read(nonce)
+
+	return base64.StdEncoding.EncodeToString(nonce)
+}
+
+// The nonce that the Content-Security-Policy of the request's response permits.
+func securityHeadersNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(securityHeadersNonceKey{}).(string)
+
+	return nonce
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+func (httpServer *BasicHTTPServer) getSecurityHeaders(serviceName string) SecurityHeaders {
+	_, result, _ := lookupRoute(defaultSecurityHeaders, serviceName)
+	_, override, _ := lookupRoute(httpServer.SecurityHeaders, serviceName)
+
+	if len(override.ContentSecurityPolicy) > 0 {
+		result.ContentSecurityPolicy = override.ContentSecurityPolicy
+	}
+
+	if len(override.FrameOptions) > 0 {
+		result.FrameOptions = override.FrameOptions
+	}
+
+	if len(override.PermissionsPolicy) > 0 {
+		result.PermissionsPolicy = override.PermissionsPolicy
+	}
+
+	if len(override.ReferrerPolicy) > 0 {
+		result.ReferrerPolicy = override.ReferrerPolicy
+	}
+
+	return result
+}
+
+// Wrap a handler so that its responses carry the service's security headers.
+func (httpServer *BasicHTTPServer) securityHeadersHandler(serviceName string, next http.Handler) http.Handler {
+	securityHeaders := httpServer.getSecurityHeaders(serviceName)
+	hstsValue := ""
+
+	if httpServer.HSTSMaxAge > 0 {
+		hstsValue = "max-age=" + strconv.Itoa(httpServer.HSTSMaxAge) + "; includeSubDomains"
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+
+		if strings.Contains(securityHeaders.ContentSecurityPolicy, securityHeadersNoncePlaceholder) {
+			nonce := newSecurityHeadersNonce()
+			request = request.WithContext(context.WithValue(request.Context(), securityHeadersNonceKey{}, nonce))
+			header.Set(
+				"Content-Security-Policy",
+				strings.ReplaceAll(securityHeaders.ContentSecurityPolicy, securityHeadersNoncePlaceholder, nonce),
+			)
+		} else if len(securityHeaders.ContentSecurityPolicy) > 0 {
+			header.Set("Content-Security-Policy", securityHeaders.ContentSecurityPolicy)
+		}
+
+		if len(securityHeaders.FrameOptions) > 0 {
+			header.Set("X-Frame-Options", securityHeaders.FrameOptions)
+		}
+
+		if len(securityHeaders.PermissionsPolicy) > 0 {
+			header.Set("Permissions-Policy", securityHeaders.PermissionsPolicy)
+		}
+
+		if len(securityHeaders.ReferrerPolicy) > 0 {
+			header.Set("Referrer-Policy", securityHeaders.ReferrerPolicy)
+		}
+
+		if len(hstsValue) > 0 && httpServer.isHTTPS(request) {
+			header.Set("Strict-Transport-Security", hstsValue)
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Report whether a request was made over TLS, to this server or to a TrustedProxies proxy in front of it.
+// Any client can send X-Forwarded-Proto, so it is only believed from TrustedProxies.
+func (httpServer *BasicHTTPServer) isHTTPS(request *http.Request) bool {
+	if request.TLS != nil {
+		return true
+	}
+
+	if request.Header.Get("X-Forwarded-Proto") != "https" {
+		return false
+	}
+
+	address, err := netip.ParseAddr(clientIP(request))
+
+	return err == nil && prefixesContain(httpServer.TrustedProxies, address.Unmap().WithZone(""))
+}
@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+/*
+The health of the services that are ServiceHealthCheckers.  They are checked on a timer, as the
+gRPC balancer checks its targets, so that loading the Console overview never causes a check.
+*/
+type serviceHealth struct {
+	errs  map[string]error // Of the last health check, keyed by service name.  Absent until the first check.
+	mutex sync.RWMutex
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const serviceHealthCheckInterval = 15 * time.Second
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func newServiceHealth() *serviceHealth {
+	return &serviceHealth{
+		errs:  map[string]error{},
+		mutex: sync.RWMutex{},
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Report whether a service passed its last health check.  A service not yet checked has not.
+func (health *serviceHealth) isHealthy(serviceName string) bool {
+	health.mutex.RLock()
+	defer health.mutex.RUnlock()
+
+	err, isChecked := health.errs[serviceName]
+
+	return isChecked && err == nil
+}
+
+// Health check a service until the context is done.
+func (health *serviceHealth) watch(ctx context.Context, serviceName string, healthChecker ServiceHealthChecker) {
+	go func() {
+		for {
+			err := healthChecker.CheckHealth(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			health.mutex.Lock()
+			health.errs[serviceName] = err
+			health.mutex.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(serviceHealthCheckInterval):
+			}
+		}
+	}()
+}
@@ -0,0 +1,284 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// ConsoleCard describes how a Service is listed on the Console overview.
+type ConsoleCard struct {
+	CommandLineOption   string // The option that enables the service, e.g. "--enable-xterm".
+	EnvironmentVariable string // The environment variable that enables the service.
+	Title               string
+}
+
+// ConsoleService is a row of the Console overview.
+type ConsoleService struct {
+	ConsoleCard
+	Status string // "green" if enabled, "orange" if not yet healthy or disabled for maintenance, otherwise "red".
+	URL    string // Empty if the service is not enabled or is switched off through the admin API.
+}
+
+// The Service implementation used by the built-in services.
+type basicService struct {
+	bannerName  string
+	consoleCard ConsoleCard
+	handler     func(ctx context.Context) (http.Handler, error)
+	isEnabled   bool
+	name        string
+	routePrefix string
+}
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+// The route prefix of the Console's templated pages.
+const routePrefixSite = "site"
+
+// ----------------------------------------------------------------------------
+// Public methods
+// ----------------------------------------------------------------------------
+
+/*
+The RegisterService method adds a service to be mounted, listed on the Console overview,
+and reported at startup alongside the built-in services.
+Services must be registered before Serve or Handler is called.
+
+Input
+  - service: The service to add.  Its name and route prefix must be unique.
+*/
+func (httpServer *BasicHTTPServer) RegisterService(service Service) {
+	httpServer.services = append(httpServer.services, service)
+}
+
+func (service *basicService) Banner(serviceURL string) string {
+	return fmt.Sprintf("Serving %-16s at %s", service.bannerName, serviceURL)
+}
+
+func (service *basicService) ConsoleCard() ConsoleCard {
+	return service.consoleCard
+}
+
+func (service *basicService) Handler(ctx context.Context) (http.Handler, error) {
+	return service.handler(ctx)
+}
+
+func (service *basicService) IsEnabled() bool {
+	return service.isEnabled
+}
+
+func (service *basicService) Name() string {
+	return service.name
+}
+
+func (service *basicService) RoutePrefix() string {
+	return service.routePrefix
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Mount every enabled service and return the messages describing them.
+func (httpServer *BasicHTTPServer) addServicesToMux(ctx context.Context, rootMux *http.ServeMux) ([]string, error) {
+	var result []string
+
+	services, err := httpServer.getServices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range services {
+		if !service.IsEnabled() {
+			continue
+		}
+
+		handler, err := service.Handler(ctx)
+		if err != nil {
+			return nil, wraperror.Errorf(err, "service %s", service.Name())
+		}
+
+		if healthChecker, isHealthChecker := service.(ServiceHealthChecker); isHealthChecker {
+			httpServer.serviceHealth.watch(ctx, service.Name(), healthChecker)
+		}
+
+		// Switching off the admin API would leave no way to switch it back on.
+		if service.Name() != ServiceNameAdmin {
+			httpServer.serviceSwitches.add(service.Name())
+		}
+
+		routePrefix := "/" + service.RoutePrefix()
+		rootMux.Handle(
+			routePrefix+"/",
+			httpServer.serviceHandler(service.Name(), http.StripPrefix(routePrefix, handler)),
+		)
+		result = append(
+			result,
+			service.Banner(fmt.Sprintf("http://localhost:%d%s", httpServer.ServerPort, routePrefix)),
+		)
+	}
+
+	return result, nil
+}
+
+// The built-in services, in the order they are listed.
+func (httpServer *BasicHTTPServer) getBuiltInServices() []Service {
+	return []Service{
+		&basicService{
+			bannerName: "Senzing REST API",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--enable-senzing-rest-api",
+				EnvironmentVariable: "SENZING_TOOLS_ENABLE_SENZING_REST_API",
+				Title:               "Senzing API Server",
+			},
+			handler: func(ctx context.Context) (http.Handler, error) {
+				return httpServer.getSenzingAPIMux(ctx)
+			},
+			isEnabled:   httpServer.EnableAll || httpServer.EnableSenzingRestAPI,
+			name:        ServiceNameAPI,
+			routePrefix: httpServer.APIUrlRoutePrefix,
+		},
+		&basicService{
+			bannerName: "SwaggerUI",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--enable-swagger-ui",
+				EnvironmentVariable: "SENZING_TOOLS_ENABLE_SWAGGER_UI",
+				Title:               "Swagger UI",
+			},
+			handler: func(ctx context.Context) (http.Handler, error) {
+				return httpServer.getSwaggerUIMux(ctx)
+			},
+			isEnabled:   httpServer.EnableAll || httpServer.EnableSwaggerUI,
+			name:        ServiceNameSwagger,
+			routePrefix: httpServer.SwaggerURLRoutePrefix,
+		},
+		&basicService{
+			bannerName: "gRPC-Web",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--enable-grpc-web",
+				EnvironmentVariable: "SENZING_TOOLS_ENABLE_GRPC_WEB",
+				Title:               "Senzing gRPC-Web",
+			},
+			handler:     httpServer.getGrpcWebHandler,
+			isEnabled:   (httpServer.EnableAll || httpServer.EnableGrpcWeb) && httpServer.hasGrpcTarget(),
+			name:        ServiceNameGrpcWeb,
+			routePrefix: httpServer.GrpcWebURLRoutePrefix,
+		},
+		&basicService{
+			bannerName: "XTerm",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--enable-xterm",
+				EnvironmentVariable: "SENZING_TOOLS_ENABLE_XTERM",
+				Title:               "XTerm",
+			},
+			handler: func(ctx context.Context) (http.Handler, error) {
+				err := os.Setenv("SENZING_ENGINE_CONFIGURATION_JSON", httpServer.SenzingSettings)
+				if err != nil {
+					return nil, wraperror.Errorf(err, "Setenv")
+				}
+
+				return httpServer.getXtermMux(ctx), nil
+			},
+			isEnabled:   httpServer.EnableAll || httpServer.EnableXterm,
+			name:        ServiceNameXterm,
+			routePrefix: httpServer.XtermURLRoutePrefix,
+		},
+		&basicService{
+			bannerName: "Admin API",
+			consoleCard: ConsoleCard{
+				CommandLineOption:   "--admin-token",
+				EnvironmentVariable: "SENZING_TOOLS_ADMIN_TOKEN",
+				Title:               "Admin API",
+			},
+			handler: func(ctx context.Context) (http.Handler, error) {
+				return httpServer.adminAuthHandler(httpServer.getAdminMux(ctx)), nil
+			},
+			isEnabled:   len(httpServer.AdminToken) > 0,
+			name:        ServiceNameAdmin,
+			routePrefix: routePrefixAdmin,
+		},
+	}
+}
+
+// The Console overview rows of all services, with URLs relative to the host of the request.
+// Services that are ServiceHealthCheckers are shown with the result of their last health check.
+func (httpServer *BasicHTTPServer) getConsoleServices(request *http.Request) []ConsoleService {
+	services, _ := httpServer.getServices()
+	result := make([]ConsoleService, len(services))
+
+	for index, service := range services {
+		result[index] = ConsoleService{
+			ConsoleCard: service.ConsoleCard(),
+			Status:      "red",
+			URL:         "",
+		}
+
+		if !service.IsEnabled() || httpServer.serviceSwitches.isDisabled(service.Name()) {
+			continue
+		}
+
+		result[index].Status = "green"
+		result[index].URL = fmt.Sprintf("http://%s/%s", request.Host, service.RoutePrefix())
+
+		_, isHealthChecker := service.(ServiceHealthChecker)
+		if httpServer.maintenance.isServiceDisabled(service.Name()) ||
+			(isHealthChecker && !httpServer.serviceHealth.isHealthy(service.Name())) {
+			result[index].Status = "orange"
+		}
+	}
+
+	return result
+}
+
+// The built-in services, then the API instances, reverse proxies, and registered services.
+// Enabled services must not share a name or route prefix with each other or with the Console.
+func (httpServer *BasicHTTPServer) getServices() ([]Service, error) {
+	result := append(httpServer.getBuiltInServices(), httpServer.getAPIInstanceServices()...)
+	result = append(result, httpServer.getReverseProxyServices()...)
+	result = append(result, httpServer.services...)
+	names := map[string]bool{ServiceNameConsole: true}
+	routePrefixes := map[string]bool{routePrefixSite: true}
+
+	for _, service := range result {
+		if !service.IsEnabled() {
+			continue
+		}
+
+		switch {
+		case len(service.Name()) == 0 || len(service.RoutePrefix()) == 0:
+			return nil, wraperror.Errorf(errForPackage, "service %s needs a name and a route prefix", service.Name())
+		case names[service.Name()]:
+			return nil, wraperror.Errorf(errForPackage, "service name %s is already in use", service.Name())
+		case routePrefixes[service.RoutePrefix()]:
+			return nil, wraperror.Errorf(errForPackage, "route prefix %s is already in use", service.RoutePrefix())
+		}
+
+		names[service.Name()] = true
+		routePrefixes[service.RoutePrefix()] = true
+	}
+
+	return result, nil
+}
+
+// The services of ReverseProxies, in the order they are configured.
+func (httpServer *BasicHTTPServer) getReverseProxyServices() []Service {
+	result := make([]Service, 0, len(httpServer.ReverseProxies))
+
+	for _, reverseProxy := range httpServer.ReverseProxies {
+		result = append(result, &reverseProxyService{
+			httpServer:   httpServer,
+			reverseProxy: reverseProxy,
+		})
+	}
+
+	return result
+}
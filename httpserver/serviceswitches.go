@@ -0,0 +1,147 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// ServiceStatus is whether a service is serving, as reported and set by the admin API.
+type ServiceStatus struct {
+	IsEnabled bool   `json:"enabled"`
+	Name      string `json:"name"` // e.g. "xterm" or "api/sales".
+}
+
+/*
+Switches the services mounted by Serve or Handler on and off at runtime.
+Services that were not enabled at startup were never built, so they cannot be switched on.
+*/
+type serviceSwitches struct {
+	disabled map[string]bool
+	mutex    sync.RWMutex
+	names    []string // Of the services that can be switched, in the order they are mounted.
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+func newServiceSwitches() *serviceSwitches {
+	return &serviceSwitches{
+		disabled: map[string]bool{},
+		mutex:    sync.RWMutex{},
+		names:    nil,
+	}
+}
+
+// Respond with a JSON body.
+func writeJSON(writer http.ResponseWriter, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(writer).Encode(value)
+	if err != nil {
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Register a mounted service, enabled.
+func (switches *serviceSwitches) add(serviceName string) {
+	switches.mutex.Lock()
+	defer switches.mutex.Unlock()
+
+	switches.names = append(switches.names, serviceName)
+}
+
+// Wrap a handler so that requests for a service are refused with a 503 while it is switched off.
+func (switches *serviceSwitches) handler(serviceName string, next http.Handler) http.Handler {
+	if switches == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if switches.isDisabled(serviceName) {
+			writeProblem(writer, request, http.StatusServiceUnavailable, "service "+serviceName+" is disabled")
+
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (switches *serviceSwitches) isDisabled(serviceName string) bool {
+	if switches == nil {
+		return false
+	}
+
+	switches.mutex.RLock()
+	defer switches.mutex.RUnlock()
+
+	return switches.disabled[serviceName]
+}
+
+// Switch a service on or off.  Reports false if the service cannot be switched.
+func (switches *serviceSwitches) set(status ServiceStatus) bool {
+	switches.mutex.Lock()
+	defer switches.mutex.Unlock()
+
+	if !slices.Contains(switches.names, status.Name) {
+		return false
+	}
+
+	switches.disabled[status.Name] = !status.IsEnabled
+
+	return true
+}
+
+// GET reports a ServiceStatus for every service that can be switched.
+func (switches *serviceSwitches) statusesFunc(writer http.ResponseWriter, request *http.Request) {
+	_ = request
+
+	switches.mutex.RLock()
+
+	result := make([]ServiceStatus, 0, len(switches.names))
+	for _, name := range switches.names {
+		result = append(result, ServiceStatus{IsEnabled: !switches.disabled[name], Name: name})
+	}
+
+	switches.mutex.RUnlock()
+
+	writeJSON(writer, result)
+}
+
+// PUT switches the service named in the path on or off, from a ServiceStatus, and reports the result.
+func (switches *serviceSwitches) statusFunc(writer http.ResponseWriter, request *http.Request) {
+	var status ServiceStatus
+
+	err := json.NewDecoder(request.Body).Decode(&status)
+	if err != nil {
+		writeProblem(writer, request, http.StatusBadRequest, "body is not a service status: "+err.Error())
+
+		return
+	}
+
+	status.Name = request.PathValue("name")
+
+	if !switches.set(status) {
+		writeProblem(
+			writer,
+			request,
+			http.StatusNotFound,
+			"service "+status.Name+" was not enabled at startup, so cannot be switched",
+		)
+
+		return
+	}
+
+	writeJSON(writer, status)
+}
@@ -0,0 +1,206 @@
+package httpserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Strong ETags for assets that cannot change while the server runs.
+type staticAssetCache struct {
+	cacheControl string
+	etags        map[string]string // Keyed by request path.
+	isLazy       bool              // Learn the ETag of a path from its first complete response.
+	mutex        sync.RWMutex
+}
+
+// Hashes a response body as it is written.
+type hashingResponseWriter struct {
+	http.ResponseWriter
+
+	hash       hash.Hash
+	isComplete bool
+	statusCode int
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+/*
+The newStaticAssetCache function creates a staticAssetCache.
+
+Input
+  - maxAge: Seconds a browser may reuse an asset without revalidating.  If 0, it always revalidates.
+  - assets: Files whose ETags are computed now.  If nil, ETags are learned as assets are served.
+*/
+func newStaticAssetCache(maxAge int, assets fs.FS) (*staticAssetCache, error) {
+	result := &staticAssetCache{
+		cacheControl: formatCacheControl(maxAge),
+		etags:        map[string]string{},
+		isLazy:       assets == nil,
+		mutex:        sync.RWMutex{},
+	}
+
+	if assets == nil {
+		return result, nil
+	}
+
+	err := fs.WalkDir(assets, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(assets, filePath)
+		if err != nil {
+			return wraperror.Errorf(err, "ReadFile %s", filePath)
+		}
+
+		sum := sha256.Sum256(content)
+		etag := formatETag(sum[:])
+		result.etags["/"+filePath] = etag
+
+		// http.FileServer serves a directory's index.html at the directory and redirects the file itself.
+		if path.Base(filePath) == "index.html" {
+			directory := strings.TrimSuffix(path.Dir(filePath), ".")
+			result.etags[strings.TrimSuffix("/"+directory, "/")+"/"] = etag
+			delete(result.etags, "/"+filePath)
+		}
+
+		return nil
+	})
+
+	return result, wraperror.Errorf(err, "WalkDir")
+}
+
+// A Cache-Control header letting browsers reuse a response for maxAge seconds.  If 0, they always revalidate.
+func formatCacheControl(maxAge int) string {
+	if maxAge > 0 {
+		return "public, max-age=" + strconv.Itoa(maxAge)
+	}
+
+	return "no-cache"
+}
+
+func formatETag(sum []byte) string {
+	return `"` + base64.RawURLEncoding.EncodeToString(sum) + `"`
+}
+
+// Report whether an If-None-Match header matches an ETag, using the weak comparison RFC 9110 requires.
+// Weak comparison also matches the "W/" ETags of compressed responses.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if len(ifNoneMatch) == 0 {
+		return false
+	}
+
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+// Wrap a handler so that its assets carry ETag and Cache-Control headers and are revalidated with 304.
+func (cache *staticAssetCache) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		etag, found := cache.lookup(request.URL.Path)
+		if found {
+			header := writer.Header()
+			header.Set("Cache-Control", cache.cacheControl)
+			header.Set("Etag", etag)
+
+			if etagMatches(request.Header.Get("If-None-Match"), etag) {
+				writer.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		if !cache.isLazy || request.Method != http.MethodGet || len(request.Header.Get("Range")) > 0 {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		hashingWriter := &hashingResponseWriter{
+			ResponseWriter: writer,
+			hash:           sha256.New(),
+			isComplete:     true,
+			statusCode:     http.StatusOK,
+		}
+		next.ServeHTTP(hashingWriter, request)
+
+		if hashingWriter.isComplete && hashingWriter.statusCode == http.StatusOK {
+			cache.store(request.URL.Path, formatETag(hashingWriter.hash.Sum(nil)))
+		}
+	})
+}
+
+func (cache *staticAssetCache) lookup(requestPath string) (string, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	etag, found := cache.etags[requestPath]
+
+	return etag, found
+}
+
+func (cache *staticAssetCache) store(requestPath string, etag string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.etags[requestPath] = etag
+}
+
+func (writer *hashingResponseWriter) Flush() {
+	_ = http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+func (writer *hashingResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+func (writer *hashingResponseWriter) Write(data []byte) (int, error) {
+	_, _ = writer.hash.Write(data)
+
+	written, err := writer.ResponseWriter.Write(data)
+	if err != nil {
+		writer.isComplete = false
+	}
+
+	return written, err //nolint:wrapcheck
+}
+
+func (writer *hashingResponseWriter) WriteHeader(statusCode int) {
+	writer.statusCode = statusCode
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,133 @@
+package httpserver
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Types
+// ----------------------------------------------------------------------------
+
+// Serves a directory from disk at "/", optionally layered over the embedded Console assets.
+type staticSite struct {
+	cacheControl   string
+	directory      fs.FS
+	directoryFiles http.Handler
+	embedded       fs.FS // If nil, the directory replaces the embedded assets.
+	embeddedFiles  http.Handler
+	isSPA          bool // Serve the directory's index.html for unknown client-side routes.
+}
+
+// ----------------------------------------------------------------------------
+// Variables
+// ----------------------------------------------------------------------------
+
+// Media types of common web assets.  Go's built-in table misses some, and operating system tables get some wrong.
+var staticSiteContentTypes = map[string]string{
+	".css":         "text/css; charset=utf-8",
+	".html":        "text/html; charset=utf-8",
+	".ico":         "image/x-icon",
+	".js":          "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".mjs":         "text/javascript; charset=utf-8",
+	".otf":         "font/otf",
+	".svg":         "image/svg+xml",
+	".ttf":         "font/ttf",
+	".txt":         "text/plain; charset=utf-8",
+	".wasm":        "application/wasm",
+	".webmanifest": "application/manifest+json",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+}
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Report whether a file system can serve a request path: a file, or a directory with an index.html.
+// Directories without one are not listed.
+func staticSiteHasPath(assets fs.FS, requestPath string) bool {
+	name := strings.TrimPrefix(path.Clean("/"+requestPath), "/")
+	if len(name) == 0 {
+		name = "."
+	}
+
+	info, err := fs.Stat(assets, name)
+	if err != nil {
+		return false
+	}
+
+	if info.IsDir() {
+		_, err = fs.Stat(assets, path.Join(name, "index.html"))
+
+		return err == nil
+	}
+
+	return true
+}
+
+// Dot files, like .env or .git, are never served.  Only /.well-known is.
+func isHiddenPath(requestPath string) bool {
+	for segment := range strings.SplitSeq(requestPath, "/") {
+		if strings.HasPrefix(segment, ".") && segment != ".well-known" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Client-side routes, like /entities/42, have no file extension.  A missing /app.js is still a 404.
+func isSPARoute(request *http.Request) bool {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	return len(path.Ext(request.URL.Path)) == 0
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+func (site *staticSite) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	requestPath := request.URL.Path
+
+	switch {
+	case isHiddenPath(requestPath):
+		http.NotFound(writer, request)
+	case staticSiteHasPath(site.directory, requestPath):
+		site.serveDirectory(writer, request)
+	case site.embedded != nil && staticSiteHasPath(site.embedded, requestPath):
+		site.embeddedFiles.ServeHTTP(writer, request)
+	case site.isSPA && isSPARoute(request) && staticSiteHasPath(site.directory, "/"):
+		indexRequest := request.Clone(request.Context())
+		indexRequest.URL.Path = "/"
+		indexRequest.URL.RawPath = ""
+		site.serveDirectory(writer, indexRequest)
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+// HTML pages are always revalidated, so that a new build's pages reference its new assets.
+func (site *staticSite) serveDirectory(writer http.ResponseWriter, request *http.Request) {
+	header := writer.Header()
+	extension := path.Ext(request.URL.Path)
+
+	if extension == ".html" || strings.HasSuffix(request.URL.Path, "/") {
+		header.Set("Cache-Control", "no-cache")
+	} else {
+		header.Set("Cache-Control", site.cacheControl)
+	}
+
+	if contentType, found := staticSiteContentTypes[extension]; found {
+		header.Set("Content-Type", contentType)
+	}
+
+	site.directoryFiles.ServeHTTP(writer, request)
+}
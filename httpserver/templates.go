@@ -0,0 +1,292 @@
+package httpserver
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"text/template/parse"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const siteTemplatesDirectory = "static/templates"
+
+// ----------------------------------------------------------------------------
+// Private functions
+// ----------------------------------------------------------------------------
+
+// Parse a template and verify that every field it references exists on TemplateVariables.
+func parseTemplate(name string, text string, funcs template.FuncMap) (*template.Template, error) {
+	result, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "Parse %s", name)
+	}
+
+	dataType := reflect.TypeFor[TemplateVariables]()
+
+	for _, associated := range result.Templates() {
+		if associated.Tree == nil {
+			continue
+		}
+
+		err = validateTemplateNode(associated.Tree.Root, dataType)
+		if err != nil {
+			return nil, wraperror.Errorf(err, "template %s", associated.Name())
+		}
+	}
+
+	return result, nil
+}
+
+// Parse every template in a file system, keyed by the request path that serves it.
+func parseTemplatesFS(
+	result map[string]*template.Template,
+	templates fs.FS,
+	pathPrefix string,
+	funcs template.FuncMap,
+) error {
+	err := fs.WalkDir(templates, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case filePath != "." && strings.HasPrefix(entry.Name(), "."):
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		case entry.IsDir() || path.Ext(filePath) != ".html":
+			return nil
+		}
+
+		templateBytes, err := fs.ReadFile(templates, filePath)
+		if err != nil {
+			return wraperror.Errorf(err, "ReadFile %s", filePath)
+		}
+
+		requestPath := pathPrefix + "/" + filePath
+
+		result[requestPath], err = parseTemplate(requestPath, string(templateBytes), funcs)
+
+		return err
+	})
+
+	return wraperror.Errorf(err, "WalkDir")
+}
+
+// Walk a template's parse tree, checking the fields it references against the type of the dot.
+// The body of a "range" over a field is checked against the field's element type.
+// The body of a "with" changes the dot to a value that is not known in advance, so it is not checked.
+func validateTemplateNode(node parse.Node, dataType reflect.Type) error {
+	var err error
+
+	switch typedNode := node.(type) {
+	case *parse.ListNode:
+		if typedNode == nil {
+			return nil
+		}
+
+		for _, child := range typedNode.Nodes {
+			err = validateTemplateNode(child, dataType)
+			if err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		err = validateTemplateNode(typedNode.Pipe, dataType)
+	case *parse.PipeNode:
+		if typedNode == nil {
+			return nil
+		}
+
+		for _, command := range typedNode.Cmds {
+			err = validateTemplateNode(command, dataType)
+			if err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, argument := range typedNode.Args {
+			err = validateTemplateNode(argument, dataType)
+			if err != nil {
+				return err
+			}
+		}
+	case *parse.FieldNode:
+		_, err = resolveTemplateField(dataType, typedNode.Ident)
+	case *parse.IfNode:
+		err = validateTemplateBranch(&typedNode.BranchNode, dataType)
+	case *parse.RangeNode:
+		err = validateTemplateRange(typedNode, dataType)
+	case *parse.WithNode:
+		err = validateTemplateNode(typedNode.Pipe, dataType)
+	case *parse.TemplateNode:
+		err = validateTemplateNode(typedNode.Pipe, dataType)
+	}
+
+	return err
+}
+
+func validateTemplateBranch(branch *parse.BranchNode, dataType reflect.Type) error {
+	err := validateTemplateNode(branch.Pipe, dataType)
+	if err != nil {
+		return err
+	}
+
+	err = validateTemplateNode(branch.List, dataType)
+	if err != nil {
+		return err
+	}
+
+	return validateTemplateNode(branch.ElseList, dataType)
+}
+
+func validateTemplateRange(rangeNode *parse.RangeNode, dataType reflect.Type) error {
+	err := validateTemplateNode(rangeNode.Pipe, dataType)
+	if err != nil {
+		return err
+	}
+
+	err = validateTemplateNode(rangeNode.ElseList, dataType)
+	if err != nil {
+		return err
+	}
+
+	// Only a range over a plain field, like {{range .Services}}, has an element type known in advance.
+	pipe := rangeNode.Pipe
+	if len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+
+	fieldNode, isFieldNode := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !isFieldNode {
+		return nil
+	}
+
+	fieldType, err := resolveTemplateField(dataType, fieldNode.Ident)
+	if err != nil {
+		return err
+	}
+
+	switch fieldType.Kind() { //nolint:exhaustive
+	case reflect.Array, reflect.Map, reflect.Slice:
+		return validateTemplateNode(rangeNode.List, fieldType.Elem())
+	default:
+		return nil
+	}
+}
+
+// Follow a chain of field names, like .BasicHTTPServer.ServerPort, through a type.
+// The result is the empty interface type if the type is only known when the template is executed.
+// Templates are executed on a value, so pointer methods are only found once a pointer has been followed.
+func resolveTemplateField(dataType reflect.Type, identifiers []string) (reflect.Type, error) {
+	currentType := dataType
+	isAddressable := false
+
+	for _, identifier := range identifiers {
+		for currentType.Kind() == reflect.Pointer {
+			currentType = currentType.Elem()
+			isAddressable = true
+		}
+
+		methodType := currentType
+		if isAddressable {
+			methodType = reflect.PointerTo(currentType)
+		}
+
+		if method, found := methodType.MethodByName(identifier); found {
+			if method.Type.NumOut() == 0 {
+				return reflect.TypeFor[any](), nil
+			}
+
+			currentType = method.Type.Out(0)
+			isAddressable = false
+
+			continue
+		}
+
+		switch currentType.Kind() { //nolint:exhaustive
+		case reflect.Struct:
+			field, found := currentType.FieldByName(identifier)
+			if !found || !field.IsExported() {
+				return nil, wraperror.Errorf(errForPackage, "field %s does not exist on %s", identifier, currentType)
+			}
+
+			currentType = field.Type
+		case reflect.Map:
+			currentType = currentType.Elem()
+			isAddressable = false
+		default:
+			// Interfaces, for example, are only known when the template is executed.
+			return reflect.TypeFor[any](), nil
+		}
+	}
+
+	return currentType, nil
+}
+
+// ----------------------------------------------------------------------------
+// Private methods
+// ----------------------------------------------------------------------------
+
+/*
+The parseSiteTemplates method parses the embedded templates and those of TemplatesDirectory,
+keyed by the request path that serves them (e.g. "/site/overview.html").
+A page of TemplatesDirectory is served under /site/ and replaces an embedded page of the same name.
+*/
+func (httpServer *BasicHTTPServer) parseSiteTemplates() (map[string]*template.Template, error) {
+	result := map[string]*template.Template{}
+	funcs := httpServer.siteTemplateFuncs()
+
+	embeddedTemplates, err := fs.Sub(static, siteTemplatesDirectory)
+	if err != nil {
+		return nil, wraperror.Errorf(err, "fs.Sub")
+	}
+
+	err = parseTemplatesFS(result, embeddedTemplates, "", funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(httpServer.TemplatesDirectory) > 0 {
+		err = parseTemplatesFS(result, os.DirFS(httpServer.TemplatesDirectory), "/"+routePrefixSite, funcs)
+		if err != nil {
+			return nil, wraperror.Errorf(err, "TemplatesDirectory %s", httpServer.TemplatesDirectory)
+		}
+	}
+
+	return result, nil
+}
+
+/*
+The siteTemplateFuncs method returns the helper functions available to site templates:
+
+  - serviceURL: The path of an enabled service by name (e.g. {{serviceURL "api"}} is "/api/"), otherwise "".
+  - version: The Version of the server.
+
+The request host is the .RequestHost variable.
+*/
+func (httpServer *BasicHTTPServer) siteTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"serviceURL": func(name string) string {
+			services, _ := httpServer.getServices()
+			for _, service := range services {
+				if service.IsEnabled() && service.Name() == name {
+					return "/" + service.RoutePrefix() + "/"
+				}
+			}
+
+			return ""
+		},
+		"version": func() string {
+			return httpServer.Version
+		},
+	}
+}
@@ -0,0 +1,347 @@
+package httpserver
+
+import (
+	"errors"
+	"maps"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/senzing-garage/go-helpers/wraperror"
+)
+
+// ----------------------------------------------------------------------------
+// Constants
+// ----------------------------------------------------------------------------
+
+const maxServerPort = 65535
+
+// ----------------------------------------------------------------------------
+// Public methods
+// ----------------------------------------------------------------------------
+
+/*
+The Validate method checks the whole configuration, so that mistakes are reported before anything is mounted.
+
+Output
+  - nil, or an error joining every problem found.  Its Unwrap() []error method lists them.
+*/
+func (httpServer *BasicHTTPServer) Validate() error {
+	var errs []error
+
+	isAPIEnabled := httpServer.EnableAll || httpServer.EnableSenzingRestAPI
+	isSwaggerEnabled := httpServer.EnableAll || httpServer.EnableSwaggerUI
+	isXtermEnabled := httpServer.EnableAll || httpServer.EnableXterm
+
+	// Listening.
+
+	if httpServer.ServerPort < 1 || httpServer.ServerPort > maxServerPort {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"ServerPort %d is not between 1 and %d",
+			httpServer.ServerPort,
+			maxServerPort,
+		))
+	}
+
+	if httpServer.ReadHeaderTimeout < 0 {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"ReadHeaderTimeout %s is negative",
+			httpServer.ReadHeaderTimeout,
+		))
+	}
+
+	if _, isValid := parseLogLevelName(httpServer.LogLevelName); !isValid {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"LogLevelName %s is not one of TRACE, DEBUG, INFO, WARN, ERROR, FATAL, or PANIC",
+			httpServer.LogLevelName,
+		))
+	}
+
+	if httpServer.LogLevelRevertAfter < 0 {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"LogLevelRevertAfter %s is negative",
+			httpServer.LogLevelRevertAfter,
+		))
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{name: "CompressionMinSize", value: httpServer.CompressionMinSize},
+		{name: "CORSMaxAge", value: httpServer.CORSMaxAge},
+		{name: "HSTSMaxAge", value: httpServer.HSTSMaxAge},
+		{name: "MaxHeaderBytes", value: httpServer.MaxHeaderBytes},
+		{name: "StaticCacheMaxAge", value: httpServer.StaticCacheMaxAge},
+	} {
+		if setting.value < 0 {
+			errs = append(errs, wraperror.Errorf(errForPackage, "%s %d is negative", setting.name, setting.value))
+		}
+	}
+
+	// Any site could make credentialed reads if "*" were answered with its origin and credentials allowed.
+	if httpServer.CORSAllowCredentials && slices.Contains(httpServer.CORSAllowedOrigins, "*") {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"CORSAllowedOrigins \"*\" cannot be combined with CORSAllowCredentials; list the origins instead",
+		))
+	}
+
+	// Services.
+
+	if (isAPIEnabled || isXtermEnabled) && len(httpServer.SenzingSettings) == 0 && !httpServer.hasGrpcTarget() {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"the Senzing REST API and XTerm need SenzingSettings or a GrpcTarget",
+		))
+	}
+
+	pathSegments, err := apiPathSegments()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// The local Senzing engine is initialized once, with the settings of whichever API starts first.
+	localEngineUsers := 0
+	if isAPIEnabled && !httpServer.hasGrpcTarget() {
+		localEngineUsers++
+	}
+
+	for _, instance := range httpServer.APIInstances {
+		switch {
+		case len(instance.Name) == 0 || strings.Contains(instance.Name, "/"):
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"API instance name %s is empty or contains a '/'",
+				instance.Name,
+			))
+		case slices.Contains([]string{RouteClassRead, RouteClassSearch, RouteClassWrite}, instance.Name):
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"API instance name %s is a route class of the Senzing REST API",
+				instance.Name,
+			))
+		case slices.Contains(pathSegments, instance.Name):
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"API instance name %s is a path of the Senzing REST API",
+				instance.Name,
+			))
+		}
+
+		switch {
+		case len(instance.GrpcTarget) > 0:
+		case len(instance.SenzingSettings) == 0:
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"API instance %s needs SenzingSettings or a GrpcTarget",
+				instance.Name,
+			))
+		default:
+			localEngineUsers++
+		}
+	}
+
+	if localEngineUsers > 1 {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"only one Senzing REST API can use SenzingSettings, because the local Senzing engine is shared by the "+
+				"whole process; the others need a GrpcTarget",
+		))
+	}
+
+	if _, isValid := grpcLoadBalancingPolicies[httpServer.GrpcLoadBalancing]; !isValid {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"GrpcLoadBalancing %s is not one of round_robin or least_request",
+			httpServer.GrpcLoadBalancing,
+		))
+	}
+
+	if len(httpServer.GrpcTarget) > 0 && len(httpServer.GrpcTargets) > 0 {
+		errs = append(errs, wraperror.Errorf(errForPackage, "GrpcTarget and GrpcTargets cannot both be set"))
+	}
+
+	if slices.Contains(httpServer.GrpcTargets, "") {
+		errs = append(errs, wraperror.Errorf(errForPackage, "GrpcTargets contains an empty target"))
+	}
+
+	if httpServer.EnableGrpcWeb && !httpServer.hasGrpcTarget() {
+		errs = append(errs, wraperror.Errorf(errForPackage, "the gRPC-Web gateway needs a GrpcTarget"))
+	}
+
+	if isSwaggerEnabled {
+		_, err := parseTemplate("OpenApiTemplate", string(httpServer.OpenAPISpecificationRest), nil)
+		if len(httpServer.OpenAPISpecificationRest) == 0 || err != nil {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"the Swagger UI needs a valid OpenAPISpecificationRest: %v",
+				err,
+			))
+		}
+	}
+
+	if isXtermEnabled {
+		if len(strings.TrimSpace(httpServer.XtermCommand)) == 0 {
+			errs = append(errs, wraperror.Errorf(errForPackage, "XTerm is enabled, but XtermCommand is empty"))
+		}
+
+		if httpServer.XtermConnectionErrorLimit <= 0 || httpServer.XtermKeepalivePingTimeout <= 0 ||
+			httpServer.XtermMaxBufferSizeBytes <= 0 {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"XtermConnectionErrorLimit, XtermKeepalivePingTimeout, and XtermMaxBufferSizeBytes must be positive",
+			))
+		}
+	}
+
+	services, err := httpServer.getServices()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, service := range services {
+		routePrefix := service.RoutePrefix()
+		if isAPIServiceName(service.Name()) {
+			// API instances are mounted under the route prefix of the Senzing REST API.
+			routePrefix = strings.TrimPrefix(routePrefix, httpServer.APIUrlRoutePrefix+"/")
+		}
+
+		if service.IsEnabled() && strings.Contains(routePrefix, "/") {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"route prefix %s of service %s contains a '/'",
+				service.RoutePrefix(),
+				service.Name(),
+			))
+		}
+	}
+
+	// Built from every source, because services is nil if getServices failed.
+	serviceNames := map[string]bool{ServiceNameConsole: true}
+	for _, service := range slices.Concat(
+		httpServer.getBuiltInServices(),
+		httpServer.getAPIInstanceServices(),
+		httpServer.getReverseProxyServices(),
+		httpServer.services,
+	) {
+		serviceNames[service.Name()] = true
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value map[string][]netip.Prefix
+	}{
+		{name: "ServiceAllowedCIDRs", value: httpServer.ServiceAllowedCIDRs},
+		{name: "ServiceDeniedCIDRs", value: httpServer.ServiceDeniedCIDRs},
+	} {
+		for _, serviceName := range slices.Sorted(maps.Keys(setting.value)) {
+			if !serviceNames[serviceName] {
+				errs = append(errs, wraperror.Errorf(
+					errForPackage,
+					"%s names an unknown service %s",
+					setting.name,
+					serviceName,
+				))
+			}
+		}
+	}
+
+	for _, reverseProxy := range httpServer.ReverseProxies {
+		_, err := parseUpstreamURL(reverseProxy.UpstreamURL)
+		if err != nil {
+			errs = append(errs, wraperror.Errorf(err, "reverse proxy %s", reverseProxy.Name))
+		}
+
+		if reverseProxy.Timeout < 0 {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"Timeout %s of reverse proxy %s is negative",
+				reverseProxy.Timeout,
+				reverseProxy.Name,
+			))
+		}
+	}
+
+	if len(httpServer.StaticDirectory) > 0 {
+		info, err := os.Stat(httpServer.StaticDirectory)
+		if err != nil || !info.IsDir() {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"StaticDirectory %s is not a readable directory",
+				httpServer.StaticDirectory,
+			))
+		}
+	} else if httpServer.StaticDirectoryExclusive || httpServer.StaticSPAFallback {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"StaticDirectoryExclusive and StaticSPAFallback need a StaticDirectory",
+		))
+	}
+
+	if len(httpServer.TemplatesDirectory) > 0 {
+		info, err := os.Stat(httpServer.TemplatesDirectory)
+		if err != nil || !info.IsDir() {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"TemplatesDirectory %s is not a readable directory",
+				httpServer.TemplatesDirectory,
+			))
+		} else if _, err = httpServer.parseSiteTemplates(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(httpServer.MirrorURL) > 0 {
+		_, err := parseUpstreamURL(httpServer.MirrorURL)
+		if err != nil {
+			errs = append(errs, wraperror.Errorf(err, "MirrorURL"))
+		}
+
+		if len(httpServer.MirrorReportFile) == 0 {
+			errs = append(errs, wraperror.Errorf(errForPackage, "mirroring to MirrorURL needs a MirrorReportFile"))
+		}
+	}
+
+	if httpServer.MirrorPercent < 0 || httpServer.MirrorPercent > 100 {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"MirrorPercent %d is not between 0 and 100",
+			httpServer.MirrorPercent,
+		))
+	}
+
+	if httpServer.MaintenanceRetryAfter < 0 {
+		errs = append(errs, wraperror.Errorf(
+			errForPackage,
+			"MaintenanceRetryAfter %d is negative",
+			httpServer.MaintenanceRetryAfter,
+		))
+	}
+
+	// Request handling.
+
+	for _, name := range slices.Sorted(maps.Keys(httpServer.RateLimits)) {
+		rateLimit := httpServer.RateLimits[name]
+		if rateLimit.RequestsPerSecond <= 0 || rateLimit.Burst <= 0 {
+			errs = append(errs, wraperror.Errorf(
+				errForPackage,
+				"rate limit %s needs a positive RequestsPerSecond and Burst",
+				name,
+			))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(httpServer.MaxRequestBodyBytes)) {
+		if httpServer.MaxRequestBodyBytes[name] < 0 {
+			errs = append(errs, wraperror.Errorf(errForPackage, "MaxRequestBodyBytes of %s is negative", name))
+		}
+	}
+
+	return errors.Join(errs...)
+}